@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// Each subcommand gets the remaining command-line arguments and returns an error
+// that is printed before exiting with a non-zero status.
+var commands = map[string]func(args []string) error{
+	"watch":       watch,
+	"serve":       serve,
+	"leaderboard": leaderboard,
+	"inputs":      inputs,
+}
+
+func usage() {
+	fmt.Println("Usage: aoc <command> [flags]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  watch          re-run a day whenever its source or input files change")
+	fmt.Println("  serve          start a local web page and HTTP API for running the solvers")
+	fmt.Println("  leaderboard    show stars, scores and completion times from a private leaderboard")
+	fmt.Println("  inputs         encrypt or decrypt the committed puzzle inputs (encrypt, decrypt, keygen)")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	command, exists := commands[os.Args[1]]
+	if !exists {
+		fmt.Println("Unknown command:", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err := command(os.Args[2:]); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+}
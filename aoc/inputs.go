@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
+)
+
+// inputs migrates the days' input.txt files to and from their encrypted
+// input.txt.enc form. Sample inputs are published with the puzzles and stay
+// in plain text.
+func inputs(args []string) error {
+	if len(args) < 1 {
+		return errors.New("inputs needs a subcommand: encrypt, decrypt or keygen")
+	}
+	action := args[0]
+	if action == "keygen" {
+		key, err := input.NewKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+		return nil
+	}
+	if action != "encrypt" && action != "decrypt" {
+		return fmt.Errorf("unknown inputs subcommand %q", action)
+	}
+
+	flags := flag.NewFlagSet("inputs "+action, flag.ContinueOnError)
+	day := flags.Int("day", 0, "only this day, 0 for all days")
+	remove := flags.Bool("remove", false, "delete the source file once converted")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	key, err := input.Key()
+	if err != nil {
+		return err
+	}
+	root, err := findRoot()
+	if err != nil {
+		return err
+	}
+	days := []int{*day}
+	if *day == 0 {
+		if days, err = availableDays(root); err != nil {
+			return err
+		}
+	}
+
+	for _, day := range days {
+		dir, err := dayDir(root, day)
+		if err != nil {
+			return err
+		}
+		plainPath := filepath.Join(dir, "input.txt")
+		encryptedPath := plainPath + input.EncryptedSuffix
+		source, target := plainPath, encryptedPath
+		if action == "decrypt" {
+			source, target = encryptedPath, plainPath
+		}
+		contents, err := os.ReadFile(source)
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("day%d: no %s, skipping\n", day, filepath.Base(source))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		var converted []byte
+		if action == "encrypt" {
+			converted, err = input.Encrypt(key, contents)
+		} else {
+			converted, err = input.Decrypt(key, contents)
+		}
+		if err != nil {
+			return fmt.Errorf("day%d: %w", day, err)
+		}
+		if err := os.WriteFile(target, converted, 0o644); err != nil {
+			return err
+		}
+		if *remove {
+			if err := os.Remove(source); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("day%d: wrote %s\n", day, filepath.Base(target))
+	}
+	return nil
+}
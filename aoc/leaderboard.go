@@ -0,0 +1,326 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The private leaderboard JSON, as served by
+// https://adventofcode.com/2024/leaderboard/private/view/<id>.json
+type Leaderboard struct {
+	Event   string             `json:"event"`
+	OwnerID int                `json:"owner_id"`
+	Members map[string]*Member `json:"members"`
+}
+
+type Member struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Stars       int    `json:"stars"`
+	LocalScore  int    `json:"local_score"`
+	GlobalScore int    `json:"global_score"`
+	LastStarTs  int64  `json:"last_star_ts"`
+	// day -> part -> star
+	CompletionDayLevel map[string]map[string]struct {
+		GetStarTs int64 `json:"get_star_ts"`
+		StarIndex int   `json:"star_index"`
+	} `json:"completion_day_level"`
+}
+
+// DayStats is one member's result for one day. Times are in seconds from the
+// moment the puzzle unlocked; zero means the star wasn't earned.
+type DayStats struct {
+	Day   int   `json:"day"`
+	Part1 int64 `json:"part1_seconds,omitempty"`
+	Part2 int64 `json:"part2_seconds,omitempty"`
+	Delta int64 `json:"delta_seconds,omitempty"` // time between part 1 and part 2
+}
+
+type MemberStats struct {
+	Name       string     `json:"name"`
+	Stars      int        `json:"stars"`
+	LocalScore int        `json:"local_score"`
+	Days       []DayStats `json:"days"`
+}
+
+// LocalDay is how far a day is solved in this repository. Answered is false
+// when the day has a solver but no answers.txt, so both parts are assumed.
+type LocalDay struct {
+	Day      int  `json:"day"`
+	Part1    bool `json:"part1"`
+	Part2    bool `json:"part2"`
+	Answered bool `json:"answered"`
+}
+
+type LeaderboardStats struct {
+	Event   string        `json:"event"`
+	Members []MemberStats `json:"members"`
+	Local   []LocalDay    `json:"local"` // days with a solution in this repository
+}
+
+func (m *Member) displayName() string {
+	if m.Name == "" {
+		return fmt.Sprintf("(anonymous user #%d)", m.ID)
+	}
+	return m.Name
+}
+
+// unlockTime is when a day's puzzle became available: midnight US Eastern
+// (UTC-5) on that day of December.
+func unlockTime(year, day int) time.Time {
+	return time.Date(year, time.December, day, 5, 0, 0, 0, time.UTC)
+}
+
+func loadLeaderboard(source string, session string) (*Leaderboard, error) {
+	var reader io.Reader
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		request, err := http.NewRequest(http.MethodGet, source, nil)
+		if err != nil {
+			return nil, err
+		}
+		if session != "" {
+			request.AddCookie(&http.Cookie{Name: "session", Value: session})
+		}
+		request.Header.Set("User-Agent", "github.com/harvardpan/advent-of-code-2024")
+		client := &http.Client{Timeout: 30 * time.Second}
+		response, err := client.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching %s: %s", source, response.Status)
+		}
+		reader = response.Body
+	} else {
+		file, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader = file
+	}
+	var leaderboard Leaderboard
+	if err := json.NewDecoder(reader).Decode(&leaderboard); err != nil {
+		return nil, fmt.Errorf("decoding leaderboard: %w", err)
+	}
+	return &leaderboard, nil
+}
+
+// computeStats works out stars, completion times and local scores. The local
+// score is recomputed the way the site does it: for every day and part, the
+// first member to get the star earns as many points as there are members, the
+// second one point less, and so on.
+func computeStats(leaderboard *Leaderboard) (*LeaderboardStats, error) {
+	year, err := strconv.Atoi(leaderboard.Event)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected event %q", leaderboard.Event)
+	}
+	type star struct {
+		member *MemberStats
+		ts     int64
+	}
+	stars := make(map[[2]int][]star) // [day, part] -> stars earned
+	members := make([]*Member, 0, len(leaderboard.Members))
+	for _, member := range leaderboard.Members {
+		members = append(members, member)
+	}
+	// stable order for ties on timestamps
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+
+	stats := make([]*MemberStats, 0, len(members))
+	for _, member := range members {
+		memberStats := &MemberStats{Name: member.displayName(), Days: make([]DayStats, 0)}
+		for dayKey, parts := range member.CompletionDayLevel {
+			day, err := strconv.Atoi(dayKey)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected day %q for %s", dayKey, memberStats.Name)
+			}
+			dayStats := DayStats{Day: day}
+			unlocked := unlockTime(year, day)
+			for partKey, partStar := range parts {
+				part, err := strconv.Atoi(partKey)
+				if err != nil || part < 1 || part > 2 {
+					return nil, fmt.Errorf("unexpected part %q for %s", partKey, memberStats.Name)
+				}
+				elapsed := partStar.GetStarTs - unlocked.Unix()
+				if part == 1 {
+					dayStats.Part1 = elapsed
+				} else {
+					dayStats.Part2 = elapsed
+				}
+				memberStats.Stars++
+				stars[[2]int{day, part}] = append(stars[[2]int{day, part}], star{member: memberStats, ts: partStar.GetStarTs})
+			}
+			if dayStats.Part1 > 0 && dayStats.Part2 > 0 {
+				dayStats.Delta = dayStats.Part2 - dayStats.Part1
+			}
+			memberStats.Days = append(memberStats.Days, dayStats)
+		}
+		sort.Slice(memberStats.Days, func(i, j int) bool { return memberStats.Days[i].Day < memberStats.Days[j].Day })
+		stats = append(stats, memberStats)
+	}
+	for _, earned := range stars {
+		sort.SliceStable(earned, func(i, j int) bool { return earned[i].ts < earned[j].ts })
+		for rank, s := range earned {
+			s.member.LocalScore += len(members) - rank
+		}
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].LocalScore != stats[j].LocalScore {
+			return stats[i].LocalScore > stats[j].LocalScore
+		}
+		return stats[i].Stars > stats[j].Stars
+	})
+
+	result := &LeaderboardStats{Event: leaderboard.Event, Members: make([]MemberStats, 0, len(stats))}
+	for _, memberStats := range stats {
+		result.Members = append(result.Members, *memberStats)
+	}
+	return result, nil
+}
+
+// localDays lists the days solved in this repository, part by part. A part
+// counts when answers.txt records its answer for input.txt. A day without
+// answers.txt counts as solved when its directory has Go files.
+func localDays(root string) ([]LocalDay, error) {
+	days, err := availableDays(root)
+	if err != nil {
+		return nil, err
+	}
+	local := make([]LocalDay, 0)
+	for _, day := range days {
+		dir, err := dayDir(root, day)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(filepath.Join(dir, "answers.txt")); errors.Is(err, os.ErrNotExist) {
+			sources, err := filepath.Glob(filepath.Join(dir, "*.go"))
+			if err != nil {
+				return nil, err
+			}
+			if len(sources) > 0 {
+				local = append(local, LocalDay{Day: day, Part1: true, Part2: true})
+			}
+			continue
+		}
+		answers, err := loadAnswers(dir)
+		if err != nil {
+			return nil, err
+		}
+		localDay := LocalDay{Day: day, Answered: true}
+		_, localDay.Part1 = answers["input.txt"][1]
+		_, localDay.Part2 = answers["input.txt"][2]
+		if localDay.Part1 || localDay.Part2 {
+			local = append(local, localDay)
+		}
+	}
+	return local, nil
+}
+
+// state shows whether a part is solved locally: yes, "code" when only the
+// solver is there to go by, or "-".
+func (localDay LocalDay) state(solved bool) string {
+	switch {
+	case !solved:
+		return "-"
+	case !localDay.Answered:
+		return "code"
+	}
+	return "yes"
+}
+
+// formatElapsed prints a completion time as h:mm:ss, or days and hours for
+// stars earned long after the unlock.
+func formatElapsed(seconds int64) string {
+	if seconds <= 0 {
+		return "-"
+	}
+	d := time.Duration(seconds) * time.Second
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd%02dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+	return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+func printLeaderboard(stats *LeaderboardStats) {
+	fmt.Printf("Advent of Code %s\n\n", stats.Event)
+	fmt.Printf("%4s  %-24s %5s %6s\n", "Rank", "Name", "Stars", "Score")
+	for i, member := range stats.Members {
+		fmt.Printf("%4d  %-24s %5d %6d\n", i+1, member.Name, member.Stars, member.LocalScore)
+	}
+
+	local := make(map[int]LocalDay)
+	for _, localDay := range stats.Local {
+		local[localDay.Day] = localDay
+	}
+	lastDay := 0
+	for _, member := range stats.Members {
+		for _, day := range member.Days {
+			lastDay = max(lastDay, day.Day)
+		}
+	}
+	for _, localDay := range stats.Local {
+		lastDay = max(lastDay, localDay.Day)
+	}
+	for _, member := range stats.Members {
+		fmt.Printf("\n%s\n", member.Name)
+		fmt.Printf("%4s %7s %7s  %10s %10s %10s\n", "Day", "Local 1", "Local 2", "Part 1", "Part 2", "Delta")
+		byDay := make(map[int]DayStats)
+		for _, day := range member.Days {
+			byDay[day.Day] = day
+		}
+		for day := 1; day <= lastDay; day++ {
+			localDay := local[day]
+			dayStats := byDay[day]
+			fmt.Printf("%4d %7s %7s  %10s %10s %10s\n", day, localDay.state(localDay.Part1), localDay.state(localDay.Part2), formatElapsed(dayStats.Part1), formatElapsed(dayStats.Part2), formatElapsed(dayStats.Delta))
+		}
+	}
+}
+
+func leaderboard(args []string) error {
+	flags := flag.NewFlagSet("leaderboard", flag.ContinueOnError)
+	source := flags.String("source", os.Getenv("AOC_LEADERBOARD"), "leaderboard JSON URL or file (default $AOC_LEADERBOARD)")
+	asJSON := flags.Bool("json", false, "print JSON instead of a table")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *source == "" {
+		return errors.New("leaderboard needs --source or $AOC_LEADERBOARD")
+	}
+	// The session cookie comes from the environment only, so it doesn't end up
+	// in shell history.
+	board, err := loadLeaderboard(*source, os.Getenv("AOC_SESSION"))
+	if err != nil {
+		return err
+	}
+	stats, err := computeStats(board)
+	if err != nil {
+		return err
+	}
+	root, err := findRoot()
+	if err != nil {
+		return err
+	}
+	stats.Local, err = localDays(root)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+	printLeaderboard(stats)
+	return nil
+}
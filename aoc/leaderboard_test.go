@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnlockTime(t *testing.T) {
+	want := time.Date(2024, time.December, 1, 5, 0, 0, 0, time.UTC).Unix()
+	if got := unlockTime(2024, 1).Unix(); got != want {
+		t.Errorf("day 1 unlocks at %d, want %d", got, want)
+	}
+	if got := unlockTime(2024, 3).Unix() - unlockTime(2024, 2).Unix(); got != 24*60*60 {
+		t.Errorf("days unlock %d seconds apart, want a day", got)
+	}
+}
+
+func TestComputeStatsSample(t *testing.T) {
+	leaderboard, err := loadLeaderboard("sampleleaderboard.json", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := computeStats(leaderboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the star timestamps are seconds after 05:00 UTC on the day
+	for i, want := range []MemberStats{
+		{Name: "harvardpan", Stars: 5, LocalScore: 14, Days: []DayStats{
+			{Day: 1, Part1: 300, Part2: 600, Delta: 300},
+			{Day: 2, Part1: 900, Part2: 1800, Delta: 900},
+			{Day: 3, Part1: 1800},
+		}},
+		{Name: "teammate", Stars: 4, LocalScore: 9, Days: []DayStats{
+			{Day: 1, Part1: 200, Part2: 1800, Delta: 1600},
+			{Day: 2, Part1: 2400, Part2: 4400, Delta: 2000},
+		}},
+		{Name: "(anonymous user #1003)", Days: []DayStats{}},
+	} {
+		if i >= len(stats.Members) {
+			t.Fatalf("got %d members, want at least %d", len(stats.Members), i+1)
+		}
+		got := stats.Members[i]
+		if got.Name != want.Name || got.Stars != want.Stars || got.LocalScore != want.LocalScore {
+			t.Errorf("member %d: got %s with %d stars and score %d, want %s with %d stars and score %d",
+				i, got.Name, got.Stars, got.LocalScore, want.Name, want.Stars, want.LocalScore)
+		}
+		if len(got.Days) != len(want.Days) {
+			t.Errorf("%s: got %d days, want %d", want.Name, len(got.Days), len(want.Days))
+			continue
+		}
+		for d, day := range want.Days {
+			if got.Days[d] != day {
+				t.Errorf("%s: got %+v, want %+v", want.Name, got.Days[d], day)
+			}
+		}
+	}
+	if len(stats.Members) != 3 {
+		t.Errorf("got %d members, want 3", len(stats.Members))
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
+)
+
+// Every day prints its answers as "The final result is: 123" (or a close variant)
+// and, from day 6 onwards, a "part1 took 1.2ms" line when each part finishes.
+var answerPattern = regexp.MustCompile(`^The (.+) is:\s*(.*)$`)
+var timerPattern = regexp.MustCompile(`(?m)^part\d+ took (\S+)$`)
+
+type PartResult struct {
+	Part     int           `json:"part"`
+	Answer   string        `json:"answer"`
+	Duration time.Duration `json:"duration"`
+	Output   string        `json:"output"` // what the part printed besides its answer, e.g. a grid
+}
+
+type RunResult struct {
+	Parts    []PartResult  `json:"parts"`
+	Output   string        `json:"output"`
+	Duration time.Duration `json:"duration"` // wall time of the whole process
+}
+
+// Part returns the result for the given part, or nil if the day didn't report one.
+func (r *RunResult) Part(part int) *PartResult {
+	for i := range r.Parts {
+		if r.Parts[i].Part == part {
+			return &r.Parts[i]
+		}
+	}
+	return nil
+}
+
+// findRoot walks up from the working directory to the directory holding go.mod.
+func findRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("could not find go.mod above the current directory")
+		}
+		dir = parent
+	}
+}
+
+func dayDir(root string, day int) (string, error) {
+	dir := filepath.Join(root, fmt.Sprintf("day%d", day))
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("day %d has no directory at %s", day, dir)
+	}
+	return dir, nil
+}
+
+// inputFiles lists the puzzle inputs of a day, the sampleinput*.txt files first
+// since they are quick to run, then input.txt. An encrypted input.txt.enc is
+// listed as input.txt, read it with input.ReadFile.
+func inputFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make([]string, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".txt") {
+			continue
+		}
+		if strings.HasPrefix(name, "sampleinput") {
+			inputs = append(inputs, name)
+		}
+	}
+	for _, name := range []string{"input.txt", "input.txt" + input.EncryptedSuffix} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			inputs = append(inputs, "input.txt")
+			break
+		}
+	}
+	return inputs, nil
+}
+
+// buildDay compiles the day's package into binDir and returns the binary path.
+// Compiler output is returned as part of the error.
+func buildDay(dir string, binDir string) (string, error) {
+	binary := filepath.Join(binDir, filepath.Base(dir))
+	cmd := exec.Command("go", "build", "-o", binary, ".")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%v\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return binary, nil
+}
+
+// runDay runs a compiled day against the given input. The days all read
+// "input.txt" from their working directory, so the input is written into a
+// scratch directory that the binary is started in, with args on its command
+// line.
+func runDay(ctx context.Context, binary string, contents []byte, args ...string) (*RunResult, error) {
+	workDir, err := os.MkdirTemp("", "aoc-run-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workDir)
+	if err := os.WriteFile(filepath.Join(workDir, "input.txt"), contents, 0o644); err != nil {
+		return nil, err
+	}
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Dir = workDir
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	start := time.Now()
+	err = cmd.Run()
+	result := parseOutput(output.String())
+	result.Duration = time.Since(start)
+	if ctx.Err() != nil {
+		return result, fmt.Errorf("run stopped: %w", ctx.Err())
+	}
+	if err != nil {
+		return result, fmt.Errorf("run failed: %w", err)
+	}
+	return result, nil
+}
+
+// parseOutput picks the answers out of a day's output. When timer lines are
+// present, each one closes a part and the last answer line before it belongs to
+// that part (the timer's own label isn't trusted, day 10 prints "part1" twice).
+// Without timers, every answer line is its own part.
+func parseOutput(output string) *RunResult {
+	result := &RunResult{Parts: make([]PartResult, 0), Output: output}
+	hasTimers := timerPattern.MatchString(output)
+	lastAnswer := ""
+	pending := false
+	var partOutput strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024) // grids can make for long lines
+	for scanner.Scan() {
+		line := scanner.Text()
+		if matches := answerPattern.FindStringSubmatch(strings.TrimSpace(line)); matches != nil {
+			lastAnswer = strings.TrimSpace(matches[2])
+			pending = true
+			if !hasTimers {
+				result.Parts = append(result.Parts, PartResult{Part: len(result.Parts) + 1, Answer: lastAnswer, Output: partOutput.String()})
+				partOutput.Reset()
+				pending = false
+			}
+			continue
+		}
+		if matches := timerPattern.FindStringSubmatch(strings.TrimSpace(line)); matches != nil && pending {
+			duration, _ := time.ParseDuration(matches[1])
+			result.Parts = append(result.Parts, PartResult{Part: len(result.Parts) + 1, Answer: lastAnswer, Duration: duration, Output: partOutput.String()})
+			partOutput.Reset()
+			pending = false
+			continue
+		}
+		partOutput.WriteString(line)
+		partOutput.WriteString("\n")
+	}
+	if pending {
+		// the last part printed an answer but never finished its timer
+		result.Parts = append(result.Parts, PartResult{Part: len(result.Parts) + 1, Answer: lastAnswer, Output: partOutput.String()})
+	}
+	return result
+}
+
+// loadAnswers reads a day's answers.txt, which has one line per input file:
+//
+//	sampleinput.txt 143 123
+//
+// A "-" stands for an answer that isn't known yet. The map is keyed by input
+// file name, then part number. A missing answers.txt is not an error.
+func loadAnswers(dir string) (map[string]map[int]string, error) {
+	answers := make(map[string]map[int]string)
+	file, err := os.Open(filepath.Join(dir, "answers.txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return answers, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokens := strings.Fields(line)
+		parts := make(map[int]string)
+		for i, token := range tokens[1:] {
+			if token != "-" {
+				parts[i+1] = token
+			}
+		}
+		answers[tokens[0]] = parts
+	}
+	return answers, scanner.Err()
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//go:embed index.html
+var indexPage []byte
+
+// Days whose output includes a rendered grid worth showing on the page, with
+// the flags that make them print it. The others print their grid anyway.
+var gridDays = map[int][]string{6: {"--render"}, 14: nil, 15: nil, 16: nil}
+
+const maxInputSize = 16 << 20
+
+type server struct {
+	root    string
+	binDir  string
+	timeout time.Duration
+	slots   chan struct{} // limits how many solvers run at once
+
+	mu       sync.Mutex
+	binaries map[int]*dayBinary
+}
+
+// dayBinary is a day's compiled program, built on first use. Each day has its
+// own lock, so a slow build only holds up requests for that day.
+type dayBinary struct {
+	mu   sync.Mutex
+	path string
+}
+
+type partResponse struct {
+	Day      int    `json:"day"`
+	Part     int    `json:"part"`
+	Answer   string `json:"answer"`
+	Duration string `json:"duration,omitempty"` // as reported by the day's own timer
+	Total    string `json:"total"`              // wall time of the whole run, both parts included
+	// The day programs always answer both parts, so the other part was run too.
+	TotalCovers string `json:"total_covers"`
+	Render      string `json:"render,omitempty"`
+}
+
+type errorResponse struct {
+	Error  string `json:"error"`
+	Output string `json:"output,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(value)
+}
+
+// availableDays lists the dayN directories in the repository.
+func availableDays(root string) ([]int, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	dayPattern := regexp.MustCompile(`^day(\d+)$`)
+	days := make([]int, 0)
+	for _, entry := range entries {
+		matches := dayPattern.FindStringSubmatch(entry.Name())
+		if !entry.IsDir() || matches == nil {
+			continue
+		}
+		day, _ := strconv.Atoi(matches[1])
+		days = append(days, day)
+	}
+	sort.Ints(days)
+	return days, nil
+}
+
+func (s *server) binary(day int) (string, error) {
+	s.mu.Lock()
+	binary, exists := s.binaries[day]
+	if !exists {
+		binary = &dayBinary{}
+		s.binaries[day] = binary
+	}
+	s.mu.Unlock()
+
+	binary.mu.Lock()
+	defer binary.mu.Unlock()
+	if binary.path != "" {
+		return binary.path, nil
+	}
+	dir, err := dayDir(s.root, day)
+	if err != nil {
+		return "", err
+	}
+	path, err := buildDay(dir, s.binDir)
+	if err != nil {
+		return "", err
+	}
+	binary.path = path
+	return path, nil
+}
+
+func (s *server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(indexPage)
+}
+
+func (s *server) handleDays(w http.ResponseWriter, r *http.Request) {
+	days, err := availableDays(s.root)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	type dayInfo struct {
+		Day  int  `json:"day"`
+		Grid bool `json:"grid"`
+	}
+	infos := make([]dayInfo, 0, len(days))
+	for _, day := range days {
+		_, grid := gridDays[day]
+		infos = append(infos, dayInfo{Day: day, Grid: grid})
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+func (s *server) handleSolve(w http.ResponseWriter, r *http.Request) {
+	day, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil || day <= 0 {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid day " + r.PathValue("n")})
+		return
+	}
+	part, err := strconv.Atoi(r.PathValue("p"))
+	if err != nil || (part != 1 && part != 2) {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid part " + r.PathValue("p")})
+		return
+	}
+	input, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxInputSize))
+	if err != nil {
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Error: err.Error()})
+		return
+	}
+	if len(input) == 0 {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "the request body should hold the puzzle input"})
+		return
+	}
+
+	binary, err := s.binary(day)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+		return
+	}
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-r.Context().Done():
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	result, err := runDay(ctx, binary, input, gridDays[day]...)
+	if err != nil {
+		response := errorResponse{Error: err.Error()}
+		if result != nil {
+			response.Output = result.Output
+		}
+		writeJSON(w, http.StatusUnprocessableEntity, response)
+		return
+	}
+	partResult := result.Part(part)
+	if partResult == nil {
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: fmt.Sprintf("day %d printed no answer for part %d", day, part), Output: result.Output})
+		return
+	}
+	response := partResponse{
+		Day:         day,
+		Part:        part,
+		Answer:      partResult.Answer,
+		Total:       result.Duration.String(),
+		TotalCovers: "the whole day program, both parts",
+	}
+	if partResult.Duration > 0 {
+		response.Duration = partResult.Duration.String()
+	}
+	if _, grid := gridDays[day]; grid {
+		response.Render = partResult.Output
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	timeout := flags.Duration("timeout", 2*time.Minute, "time limit for a single run")
+	workers := flags.Int("workers", 2, "how many solvers may run at the same time")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *workers < 1 {
+		return errors.New("serve needs at least one worker")
+	}
+	root, err := findRoot()
+	if err != nil {
+		return err
+	}
+	binDir, err := os.MkdirTemp("", "aoc-serve-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(binDir)
+
+	s := &server{
+		root:     filepath.Clean(root),
+		binDir:   binDir,
+		timeout:  *timeout,
+		slots:    make(chan struct{}, *workers),
+		binaries: make(map[int]*dayBinary),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /days", s.handleDays)
+	mux.HandleFunc("POST /days/{n}/parts/{p}", s.handleSolve)
+	httpServer := &http.Server{Addr: *addr, Handler: mux}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("Serving on http://%s/\n", *addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
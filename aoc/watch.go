@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
+)
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// snapshot records the modification time and size of the day's .go, .txt and
+// .enc files. Comparing two snapshots is how watch notices a change; polling keeps
+// us on the standard library.
+func snapshot(dir string) (map[string]fileStamp, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	stamps := make(map[string]fileStamp)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasSuffix(name, ".go") || strings.HasSuffix(name, ".txt") || strings.HasSuffix(name, input.EncryptedSuffix)) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// the file went away between ReadDir and Info, the next poll will notice
+			continue
+		}
+		stamps[name] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps, nil
+}
+
+func sameSnapshot(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, stamp := range a {
+		if other, exists := b[name]; !exists || other != stamp {
+			return false
+		}
+	}
+	return true
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "-"
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	case d < time.Second:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Millisecond).String()
+	}
+}
+
+// checkDay rebuilds the day and runs it against every selected input, printing
+// one PASS/FAIL/???? line per input and part.
+func checkDay(ctx context.Context, dir string, binDir string, inputs []string, parts []int, timeout time.Duration) {
+	fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), filepath.Base(dir))
+	start := time.Now()
+	binary, err := buildDay(dir, binDir)
+	if err != nil {
+		fmt.Println("  BUILD FAILED:", err)
+		return
+	}
+	fmt.Println("  built in", formatDuration(time.Since(start)))
+	answers, err := loadAnswers(dir)
+	if err != nil {
+		fmt.Println("  Error reading answers.txt:", err)
+	}
+	for _, inputName := range inputs {
+		contents, err := input.ReadFile(filepath.Join(dir, inputName))
+		if err != nil {
+			fmt.Printf("  %-18s Error reading input: %v\n", inputName, err)
+			continue
+		}
+		runCtx, cancel := context.WithTimeout(ctx, timeout)
+		result, err := runDay(runCtx, binary, contents)
+		cancel()
+		if err != nil {
+			fmt.Printf("  %-18s %v\n", inputName, err)
+		}
+		if result == nil {
+			continue
+		}
+		for _, part := range parts {
+			partResult := result.Part(part)
+			expected, known := answers[inputName][part]
+			switch {
+			case partResult == nil:
+				fmt.Printf("  MISS part%d %-18s no answer printed\n", part, inputName)
+			case !known:
+				fmt.Printf("  ???? part%d %-18s %s (%s)\n", part, inputName, partResult.Answer, formatDuration(partResult.Duration))
+			case partResult.Answer == expected:
+				fmt.Printf("  PASS part%d %-18s %s (%s)\n", part, inputName, partResult.Answer, formatDuration(partResult.Duration))
+			default:
+				fmt.Printf("  FAIL part%d %-18s got %s, want %s (%s)\n", part, inputName, partResult.Answer, expected, formatDuration(partResult.Duration))
+			}
+		}
+		if len(result.Parts) > 0 && result.Parts[0].Duration == 0 {
+			// days without their own timers only get the process wall time
+			fmt.Printf("       %-24s total %s\n", "", formatDuration(result.Duration))
+		}
+	}
+}
+
+func watch(args []string) error {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	day := flags.Int("day", 0, "day to watch (required)")
+	part := flags.Int("part", 0, "part to check, 0 for both")
+	which := flags.String("inputs", "all", "inputs to run: sample, real or all")
+	interval := flags.Duration("interval", 500*time.Millisecond, "how often to poll for changes")
+	timeout := flags.Duration("timeout", 2*time.Minute, "time limit for a single run")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *day <= 0 {
+		return errors.New("watch needs --day")
+	}
+	if *which != "all" && *which != "sample" && *which != "real" {
+		return fmt.Errorf("unknown inputs %q", *which)
+	}
+	parts := []int{1, 2}
+	if *part == 1 || *part == 2 {
+		parts = []int{*part}
+	} else if *part != 0 {
+		return fmt.Errorf("unknown part %d", *part)
+	}
+
+	root, err := findRoot()
+	if err != nil {
+		return err
+	}
+	dir, err := dayDir(root, *day)
+	if err != nil {
+		return err
+	}
+	binDir, err := os.MkdirTemp("", "aoc-watch-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(binDir)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var previous map[string]fileStamp
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		current, err := snapshot(dir)
+		if err != nil {
+			return err
+		}
+		if !sameSnapshot(previous, current) {
+			previous = current
+			inputs, err := inputFiles(dir)
+			if err != nil {
+				return err
+			}
+			selected := make([]string, 0)
+			for _, input := range inputs {
+				isSample := strings.HasPrefix(input, "sample")
+				if *which == "all" || (*which == "sample" && isSample) || (*which == "real" && !isSample) {
+					selected = append(selected, input)
+				}
+			}
+			checkDay(ctx, dir, binDir, selected, parts, *timeout)
+			fmt.Println("Watching for changes, Ctrl-C to stop.")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
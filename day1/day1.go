@@ -2,22 +2,60 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"math"
-	"os"
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
 func main() {
+	external := flag.Bool("external", false, "sort and count on disk, for lists larger than memory")
+	memoryMiB := flag.Int("mem", 256, "memory budget in MiB for --external")
+	report := flag.String("report", "", "print a pairing report instead of the answers: csv or json")
+	top := flag.Int("top", 10, "number of largest distance contributors in the report")
+	matrix := flag.Bool("matrix", false, "compare every pair of columns and print distance and similarity matrices")
+	ops := flag.String("ops", "", "apply a file of +L/-L/+R/-R operations, printing the answers after each")
+	flag.Parse()
+	if *ops != "" {
+		runOperations(*ops)
+		return
+	}
+	if *matrix {
+		printMatrices()
+		return
+	}
+	if *report != "" {
+		if *top < 0 {
+			fmt.Println("The number of top contributors can't be negative:", *top)
+			return
+		}
+		printReport(*report, *top)
+		return
+	}
+	if *external {
+		if *memoryMiB < 1 {
+			fmt.Println("The memory budget should be at least 1 MiB")
+			return
+		}
+		if *memoryMiB > math.MaxInt>>20 {
+			fmt.Println("The memory budget is too large:", *memoryMiB)
+			return
+		}
+		part1External(*memoryMiB << 20)
+		part2External(*memoryMiB << 20)
+		return
+	}
 	part1()
 	part2()
 }
 
 func part1() {
 	// https://adventofcode.com/2024/day/1
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
@@ -27,6 +65,7 @@ func part1() {
 	// declare the arrays that will store the values
 	var firstnumbers []int
 	var secondnumbers []int
+	linesWithExtraColumns := 0
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -35,6 +74,9 @@ func part1() {
 		if len(tokens) < 2 {
 			continue
 		}
+		if len(tokens) > 2 {
+			linesWithExtraColumns++
+		}
 		firstnum, err1 := strconv.Atoi(tokens[0])
 		secondnum, err2 := strconv.Atoi(tokens[1])
 		if err1 != nil || err2 != nil {
@@ -47,6 +89,9 @@ func part1() {
 	if err := scanner.Err(); err != nil {
 		fmt.Println("Error reading file:", err)
 	}
+	if linesWithExtraColumns > 0 {
+		fmt.Println("Only the first two columns are compared,", linesWithExtraColumns, "lines have more. Use --matrix to compare all of them.")
+	}
 
 	distance := 0
 	// sort the arrays
@@ -65,7 +110,7 @@ func part2() {
 	// https://adventofcode.com/2024/day/1#part2
 	// Calculate a similarity score. Multiply the number on left with the number
 	// of times that it appears on the right. Add up all the scores.
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
+)
+
+// External-memory versions of part1 and part2 for location lists that don't fit
+// in RAM. Part 1 sorts each column with an external merge sort: values are
+// collected until the memory budget is used up, then sorted and spilled to a
+// temporary "run" file, and at the end all runs are merged with a heap. Part 2
+// sorts both columns the same way and walks them side by side, so equal IDs
+// are counted as they go past instead of in a map.
+
+const (
+	valueSize     = 8  // bytes per value in a run file
+	maxMergeFanIn = 64 // runs merged at once, each holds a file open
+)
+
+// parseLocationLine reads the two IDs on a line of the input.
+func parseLocationLine(line string) (int, int, bool) {
+	tokens := strings.Fields(line)
+	if len(tokens) < 2 {
+		return 0, 0, false
+	}
+	firstnum, err1 := strconv.Atoi(tokens[0])
+	secondnum, err2 := strconv.Atoi(tokens[1])
+	if err1 != nil || err2 != nil {
+		fmt.Println("Error converting string to int")
+		return 0, 0, false
+	}
+	return firstnum, secondnum, true
+}
+
+// sortedRuns collects one column, spilling a sorted run to disk every time the
+// buffer reaches its limit.
+type sortedRuns struct {
+	dir    string
+	limit  int
+	buffer []int
+	files  []string
+}
+
+func (r *sortedRuns) add(value int) error {
+	r.buffer = append(r.buffer, value)
+	if len(r.buffer) >= r.limit {
+		return r.spill()
+	}
+	return nil
+}
+
+func (r *sortedRuns) spill() error {
+	sort.Ints(r.buffer)
+	file, err := os.CreateTemp(r.dir, "run-")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	var encoded [valueSize]byte
+	for _, value := range r.buffer {
+		binary.BigEndian.PutUint64(encoded[:], uint64(value))
+		if _, err := writer.Write(encoded[:]); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	r.files = append(r.files, file.Name())
+	r.buffer = r.buffer[:0]
+	return nil
+}
+
+// runSource is one sorted run being merged: a run file, or the values that
+// were still in memory when the input ended.
+type runSource struct {
+	file   *os.File
+	reader *bufio.Reader
+	memory []int
+	value  int // current head of the run
+}
+
+func (s *runSource) advance() (bool, error) {
+	if s.reader == nil {
+		if len(s.memory) == 0 {
+			return false, nil
+		}
+		s.value = s.memory[0]
+		s.memory = s.memory[1:]
+		return true, nil
+	}
+	var encoded [valueSize]byte
+	if _, err := io.ReadFull(s.reader, encoded[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	s.value = int(binary.BigEndian.Uint64(encoded[:]))
+	return true, nil
+}
+
+// runHeap orders the runs by their current head, smallest first.
+type runHeap []*runSource
+
+func (h runHeap) Len() int           { return len(h) }
+func (h runHeap) Less(i, j int) bool { return h[i].value < h[j].value }
+func (h runHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x any)        { *h = append(*h, x.(*runSource)) }
+func (h *runHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// runMerger is a k-way merge over all the runs of a column.
+type runMerger struct {
+	heap    runHeap
+	sources []*runSource
+}
+
+// newRunMerger opens the run files for merging, together with an optional
+// sorted run still held in memory.
+func newRunMerger(names []string, memory []int) (*runMerger, error) {
+	merger := &runMerger{heap: make(runHeap, 0)}
+	sources := []*runSource{{memory: memory}}
+	for _, name := range names {
+		file, err := os.Open(name)
+		if err != nil {
+			merger.close()
+			return nil, err
+		}
+		source := &runSource{file: file, reader: bufio.NewReader(file)}
+		merger.sources = append(merger.sources, source)
+		sources = append(sources, source)
+	}
+	for _, source := range sources {
+		ok, err := source.advance()
+		if err != nil {
+			merger.close()
+			return nil, err
+		}
+		if ok {
+			merger.heap = append(merger.heap, source)
+		}
+	}
+	heap.Init(&merger.heap)
+	return merger, nil
+}
+
+// mergeToFile merges the runs into a single new run and removes them.
+func mergeToFile(dir string, names []string) (string, error) {
+	merger, err := newRunMerger(names, nil)
+	if err != nil {
+		return "", err
+	}
+	defer merger.close()
+	file, err := os.CreateTemp(dir, "run-")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	var encoded [valueSize]byte
+	for {
+		value, ok, err := merger.next()
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			break
+		}
+		binary.BigEndian.PutUint64(encoded[:], uint64(value))
+		if _, err := writer.Write(encoded[:]); err != nil {
+			return "", err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		os.Remove(name)
+	}
+	return file.Name(), nil
+}
+
+// merge returns the column in sorted order. With more runs than can be open at
+// once, the runs are first merged in groups until few enough are left.
+func (r *sortedRuns) merge() (*runMerger, error) {
+	for len(r.files) > maxMergeFanIn {
+		merged := make([]string, 0)
+		for start := 0; start < len(r.files); start += maxMergeFanIn {
+			name, err := mergeToFile(r.dir, r.files[start:min(start+maxMergeFanIn, len(r.files))])
+			if err != nil {
+				return nil, err
+			}
+			merged = append(merged, name)
+		}
+		r.files = merged
+	}
+	sort.Ints(r.buffer)
+	return newRunMerger(r.files, r.buffer)
+}
+
+// next returns the smallest value not yet returned.
+func (m *runMerger) next() (int, bool, error) {
+	if len(m.heap) == 0 {
+		return 0, false, nil
+	}
+	source := m.heap[0]
+	value := source.value
+	ok, err := source.advance()
+	if err != nil {
+		return 0, false, err
+	}
+	if ok {
+		heap.Fix(&m.heap, 0)
+	} else {
+		heap.Pop(&m.heap)
+	}
+	return value, true, nil
+}
+
+func (m *runMerger) close() {
+	for _, source := range m.sources {
+		source.file.Close()
+	}
+}
+
+// sortColumns reads the two columns of the input into sorted runs.
+func sortColumns(reader io.Reader, dir string, memoryBudget int) (*sortedRuns, *sortedRuns, error) {
+	// the two columns share the budget
+	limit := max(1, memoryBudget/valueSize/2)
+	first := &sortedRuns{dir: dir, limit: limit, buffer: make([]int, 0, limit)}
+	second := &sortedRuns{dir: dir, limit: limit, buffer: make([]int, 0, limit)}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		firstnum, secondnum, ok := parseLocationLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		if err := first.add(firstnum); err != nil {
+			return nil, nil, err
+		}
+		if err := second.add(secondnum); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return first, second, nil
+}
+
+func externalDistance(reader io.Reader, dir string, memoryBudget int) (int, error) {
+	first, second, err := sortColumns(reader, dir, memoryBudget)
+	if err != nil {
+		return 0, err
+	}
+	fmt.Println("Sorted runs spilled to disk:", len(first.files)+len(second.files))
+
+	firstMerger, err := first.merge()
+	if err != nil {
+		return 0, err
+	}
+	defer firstMerger.close()
+	secondMerger, err := second.merge()
+	if err != nil {
+		return 0, err
+	}
+	defer secondMerger.close()
+	distance := 0
+	for {
+		firstnum, ok1, err := firstMerger.next()
+		if err != nil {
+			return 0, err
+		}
+		secondnum, ok2, err := secondMerger.next()
+		if err != nil {
+			return 0, err
+		}
+		if !ok1 || !ok2 {
+			break
+		}
+		distance += int(math.Abs(float64(firstnum - secondnum)))
+	}
+	return distance, nil
+}
+
+// sameValueCount reads past every copy of value at the head of a merged
+// column, returning how many there were and the first larger value.
+func sameValueCount(merger *runMerger, value int) (int, int, bool, error) {
+	count := 0
+	for {
+		count++
+		next, ok, err := merger.next()
+		if err != nil || !ok || next != value {
+			return count, next, ok, err
+		}
+	}
+}
+
+func externalSimilarity(reader io.Reader, dir string, memoryBudget int) (int, error) {
+	first, second, err := sortColumns(reader, dir, memoryBudget)
+	if err != nil {
+		return 0, err
+	}
+	firstMerger, err := first.merge()
+	if err != nil {
+		return 0, err
+	}
+	defer firstMerger.close()
+	secondMerger, err := second.merge()
+	if err != nil {
+		return 0, err
+	}
+	defer secondMerger.close()
+
+	// walk both sorted columns together, multiplying the counts of every ID
+	// that appears in both
+	similarity := 0
+	firstnum, ok1, err := firstMerger.next()
+	if err != nil {
+		return 0, err
+	}
+	secondnum, ok2, err := secondMerger.next()
+	if err != nil {
+		return 0, err
+	}
+	for ok1 && ok2 {
+		switch {
+		case firstnum < secondnum:
+			firstnum, ok1, err = firstMerger.next()
+		case secondnum < firstnum:
+			secondnum, ok2, err = secondMerger.next()
+		default:
+			value := firstnum
+			var firstCount, secondCount int
+			firstCount, firstnum, ok1, err = sameValueCount(firstMerger, value)
+			if err != nil {
+				return 0, err
+			}
+			secondCount, secondnum, ok2, err = sameValueCount(secondMerger, value)
+			similarity += value * firstCount * secondCount
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return similarity, nil
+}
+
+func part1External(memoryBudget int) {
+	// https://adventofcode.com/2024/day/1, with the lists sorted on disk
+	file, err := input.Open("input.txt")
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return
+	}
+	defer file.Close()
+	dir, err := os.MkdirTemp("", "day1-")
+	if err != nil {
+		fmt.Println("Error creating temporary directory:", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	distance, err := externalDistance(file, dir, memoryBudget)
+	if err != nil {
+		fmt.Println("Error computing distance:", err)
+		return
+	}
+	fmt.Println("The distance is:", distance)
+}
+
+func part2External(memoryBudget int) {
+	// https://adventofcode.com/2024/day/1#part2, with the lists sorted on disk
+	file, err := input.Open("input.txt")
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return
+	}
+	defer file.Close()
+	dir, err := os.MkdirTemp("", "day1-")
+	if err != nil {
+		fmt.Println("Error creating temporary directory:", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	similarity, err := externalSimilarity(file, dir, memoryBudget)
+	if err != nil {
+		fmt.Println("Error computing similarity:", err)
+		return
+	}
+	fmt.Println("The similarity is:", similarity)
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
+)
+
+// Incremental mode keeps both answers up to date while IDs are added to or
+// removed from either list, driven by a file of operations such as "+L 123"
+// or "-R 456". Each operation is applied as soon as its line is read.
+//
+// The similarity is the counts idea from part2: an ID x on the left scores
+// x * (occurrences of x on the right), so one operation changes it by x times
+// the count on the other side, which is a map lookup.
+//
+// The distance is harder, because adding one ID shifts the rank of every
+// larger ID in that list and re-pairs all of them. Instead of the sorted
+// pairs, it uses the equivalent form
+//
+//	distance = sum over consecutive IDs v[k] < v[k+1] of |D(v[k])| * (v[k+1] - v[k])
+//
+// where D(v) is the number of left IDs <= v minus the number of right IDs <= v.
+// Adding a left ID x adds 1 to D for every v >= x, so an operation is a suffix
+// update of D. The absolute value keeps that from being a plain sum a Fenwick
+// or segment tree could shift in one step, so the IDs seen so far are kept in
+// sorted blocks of about sqrt(n) instead: a whole block takes the update as a
+// lazy offset and answers its share of the sum with a binary search, and only
+// the block holding x is rebuilt. An ID not seen before is inserted into its
+// block, which is split once it grows to twice the size, and every time the
+// number of IDs quadruples all the blocks are cut again to the new sqrt(n).
+// An operation is O(sqrt(n) log n), plus the amortized cost of re-cutting.
+
+type Operation struct {
+	add   bool // + or -
+	right bool // L or R
+	id    int
+}
+
+func parseOperation(line string) (Operation, error) {
+	tokens := strings.Fields(line)
+	if len(tokens) != 2 || len(tokens[0]) != 2 {
+		return Operation{}, fmt.Errorf("expected an operation like \"+L 123\", got %q", line)
+	}
+	var operation Operation
+	switch tokens[0][0] {
+	case '+':
+		operation.add = true
+	case '-':
+	default:
+		return Operation{}, fmt.Errorf("unknown operation %q", tokens[0])
+	}
+	switch tokens[0][1] {
+	case 'L':
+	case 'R':
+		operation.right = true
+	default:
+		return Operation{}, fmt.Errorf("unknown list %q", tokens[0])
+	}
+	id, err := strconv.Atoi(tokens[1])
+	if err != nil {
+		return Operation{}, err
+	}
+	operation.id = id
+	return operation, nil
+}
+
+func (o Operation) String() string {
+	sign, list := "-", "L"
+	if o.add {
+		sign = "+"
+	}
+	if o.right {
+		list = "R"
+	}
+	return fmt.Sprintf("%s%s %d", sign, list, o.id)
+}
+
+// distanceBlock is a run of consecutive IDs with their D values, and the D
+// values again in sorted order, so the block's share of the distance can be
+// found for any lazy offset.
+type distanceBlock struct {
+	ids      []int // sorted
+	weights  []int // gap to the next ID, 0 for the last ID overall
+	d        []int // D per ID, without lazy
+	lazy     int   // added to every D in the block
+	sortedD  []int
+	prefixW  []int // prefix sums of the gap weights, in sortedD order
+	prefixWD []int // prefix sums of weight * D, in sortedD order
+}
+
+type IncrementalLists struct {
+	blocks     []*distanceBlock
+	blockSize  int // blocks are split when they reach twice this
+	idCount    int // distinct IDs in the blocks
+	distance   int
+	leftCounts map[int]int
+	counts     map[int]int // count the number of times the number appears in the second list
+	leftSize   int
+	rightSize  int
+	similarity int
+}
+
+func NewIncrementalLists(firstnumbers, secondnumbers []int) *IncrementalLists {
+	ids := make([]int, 0, len(firstnumbers)+len(secondnumbers))
+	ids = append(ids, firstnumbers...)
+	ids = append(ids, secondnumbers...)
+	sort.Ints(ids)
+	ids = compactSorted(ids)
+	lists := &IncrementalLists{
+		leftCounts: make(map[int]int),
+		counts:     make(map[int]int),
+		leftSize:   len(firstnumbers),
+		rightSize:  len(secondnumbers),
+	}
+	for _, id := range firstnumbers {
+		lists.leftCounts[id]++
+	}
+	for _, id := range secondnumbers {
+		lists.counts[id]++
+	}
+	weights := make([]int, len(ids))
+	d := make([]int, len(ids))
+	running := 0
+	for i, id := range ids {
+		if i+1 < len(ids) {
+			weights[i] = ids[i+1] - id
+		}
+		running += lists.leftCounts[id] - lists.counts[id]
+		d[i] = running
+		lists.similarity += id * lists.leftCounts[id] * lists.counts[id]
+	}
+	lists.layout(ids, weights, d)
+	return lists
+}
+
+// layout cuts the IDs into blocks of about sqrt(n) and works out the distance.
+func (lists *IncrementalLists) layout(ids, weights, d []int) {
+	lists.idCount = len(ids)
+	lists.blockSize = 1
+	for lists.blockSize*lists.blockSize < len(ids) {
+		lists.blockSize++
+	}
+	lists.blocks = nil
+	lists.distance = 0
+	for start := 0; start < len(ids); start += lists.blockSize {
+		end := min(start+lists.blockSize, len(ids))
+		block := &distanceBlock{
+			ids:     slices.Clone(ids[start:end]),
+			weights: slices.Clone(weights[start:end]),
+			d:       slices.Clone(d[start:end]),
+		}
+		block.rebuild()
+		lists.blocks = append(lists.blocks, block)
+		lists.distance += block.contribution()
+	}
+}
+
+// reblock cuts the blocks again once there are so many IDs that the block
+// size is well below sqrt(n), so new IDs don't leave too many small blocks.
+func (lists *IncrementalLists) reblock() {
+	if lists.idCount < 4*lists.blockSize*lists.blockSize {
+		return
+	}
+	ids := make([]int, 0, lists.idCount)
+	weights := make([]int, 0, lists.idCount)
+	d := make([]int, 0, lists.idCount)
+	for _, block := range lists.blocks {
+		ids = append(ids, block.ids...)
+		weights = append(weights, block.weights...)
+		for _, value := range block.d {
+			d = append(d, value+block.lazy)
+		}
+	}
+	lists.layout(ids, weights, d)
+}
+
+func compactSorted(values []int) []int {
+	result := values[:0]
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func (block *distanceBlock) rebuild() {
+	order := make([]int, len(block.ids))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return block.d[order[i]] < block.d[order[j]] })
+	block.sortedD = make([]int, len(order))
+	block.prefixW = make([]int, len(order)+1)
+	block.prefixWD = make([]int, len(order)+1)
+	for i, index := range order {
+		block.sortedD[i] = block.d[index]
+		block.prefixW[i+1] = block.prefixW[i] + block.weights[index]
+		block.prefixWD[i+1] = block.prefixWD[i] + block.weights[index]*block.d[index]
+	}
+}
+
+// contribution is the block's share of the distance: sum of w * |D + lazy|.
+func (block *distanceBlock) contribution() int {
+	// the first split entries have D + lazy < 0
+	split := sort.SearchInts(block.sortedD, -block.lazy)
+	n := len(block.sortedD)
+	negative := block.prefixWD[split] + block.lazy*block.prefixW[split]
+	positive := (block.prefixWD[n] - block.prefixWD[split]) + block.lazy*(block.prefixW[n]-block.prefixW[split])
+	return positive - negative
+}
+
+// update applies change to the block, keeping the distance up to date.
+func (lists *IncrementalLists) update(block *distanceBlock, change func()) {
+	lists.distance -= block.contribution()
+	change()
+	block.rebuild()
+	lists.distance += block.contribution()
+}
+
+// locate finds the block the ID belongs in and its index there, which is
+// where it would be inserted if it isn't known yet.
+func (lists *IncrementalLists) locate(id int) (int, int, bool) {
+	b := sort.Search(len(lists.blocks), func(b int) bool {
+		ids := lists.blocks[b].ids
+		return ids[len(ids)-1] >= id
+	})
+	if b == len(lists.blocks) {
+		// larger than every ID, it goes at the end of the last block
+		last := len(lists.blocks) - 1
+		return last, len(lists.blocks[last].ids), false
+	}
+	i := sort.SearchInts(lists.blocks[b].ids, id)
+	return b, i, lists.blocks[b].ids[i] == id
+}
+
+// insert adds an ID not seen before and returns where it went. Its D is the
+// D of the ID before it, and it splits that ID's gap in two, so the distance
+// doesn't change.
+func (lists *IncrementalLists) insert(id int) (int, int) {
+	lists.idCount++
+	if len(lists.blocks) == 0 {
+		block := &distanceBlock{ids: []int{id}, weights: []int{0}, d: []int{0}}
+		block.rebuild()
+		lists.blocks = append(lists.blocks, block)
+		return 0, 0
+	}
+	b, i, _ := lists.locate(id)
+	block := lists.blocks[b]
+	d, weight := 0, 0
+	if i < len(block.ids) {
+		weight = block.ids[i] - id
+	} else if b+1 < len(lists.blocks) {
+		weight = lists.blocks[b+1].ids[0] - id
+	}
+	switch {
+	case i > 0:
+		d = block.d[i-1] + block.lazy
+	case b > 0:
+		previous := lists.blocks[b-1]
+		last := len(previous.ids) - 1
+		d = previous.d[last] + previous.lazy
+		lists.update(previous, func() { previous.weights[last] = id - previous.ids[last] })
+	}
+	lists.update(block, func() {
+		if i > 0 {
+			block.weights[i-1] = id - block.ids[i-1]
+		}
+		block.ids = slices.Insert(block.ids, i, id)
+		block.weights = slices.Insert(block.weights, i, weight)
+		block.d = slices.Insert(block.d, i, d-block.lazy)
+	})
+	if len(block.ids) >= 2*lists.blockSize {
+		half := len(block.ids) / 2
+		second := &distanceBlock{
+			ids:     slices.Clone(block.ids[half:]),
+			weights: slices.Clone(block.weights[half:]),
+			d:       slices.Clone(block.d[half:]),
+			lazy:    block.lazy,
+		}
+		// the two halves have the same share of the distance as the whole
+		block.ids, block.weights, block.d = block.ids[:half], block.weights[:half], block.d[:half]
+		block.rebuild()
+		second.rebuild()
+		lists.blocks = slices.Insert(lists.blocks, b+1, second)
+		if i >= half {
+			return b + 1, i - half
+		}
+	}
+	return b, i
+}
+
+// addSuffix adds delta to D for the ID at index i of block b and every larger ID.
+func (lists *IncrementalLists) addSuffix(b, i int, delta int) {
+	block := lists.blocks[b]
+	lists.update(block, func() {
+		for k := i; k < len(block.ids); k++ {
+			block.d[k] += delta
+		}
+	})
+	for _, block := range lists.blocks[b+1:] {
+		lists.distance -= block.contribution()
+		block.lazy += delta
+		lists.distance += block.contribution()
+	}
+}
+
+// Apply performs one operation, returning an error when it removes an ID
+// that isn't in the list.
+func (lists *IncrementalLists) Apply(operation Operation) error {
+	own, other := lists.leftCounts, lists.counts
+	size := &lists.leftSize
+	delta := 1 // D counts left IDs positively
+	if operation.right {
+		own, other = lists.counts, lists.leftCounts
+		size = &lists.rightSize
+		delta = -1
+	}
+	if !operation.add && own[operation.id] == 0 {
+		return fmt.Errorf("ID %d isn't in the list", operation.id)
+	}
+	var b, i int
+	known := false
+	if len(lists.blocks) > 0 {
+		b, i, known = lists.locate(operation.id)
+	}
+	if !known {
+		lists.insert(operation.id)
+		lists.reblock()
+		b, i, _ = lists.locate(operation.id)
+	}
+	if operation.add {
+		own[operation.id]++
+		*size++
+		lists.similarity += operation.id * other[operation.id]
+	} else {
+		own[operation.id]--
+		*size--
+		lists.similarity -= operation.id * other[operation.id]
+		delta = -delta
+	}
+	lists.addSuffix(b, i, delta)
+	return nil
+}
+
+// Distance is only defined, like in part1, when both lists have the same
+// number of IDs to pair up.
+func (lists *IncrementalLists) Distance() (int, bool) {
+	return lists.distance, lists.leftSize == lists.rightSize
+}
+
+func (lists *IncrementalLists) Similarity() int {
+	return lists.similarity
+}
+
+func (lists *IncrementalLists) printState(prefix string) {
+	distance, ok := lists.Distance()
+	distanceString := strconv.Itoa(distance)
+	if !ok {
+		distanceString = fmt.Sprintf("n/a (%d left, %d right)", lists.leftSize, lists.rightSize)
+	}
+	fmt.Printf("%s distance=%s similarity=%d\n", prefix, distanceString, lists.similarity)
+}
+
+func runOperations(opsPath string) {
+	file, err := input.Open("input.txt")
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return
+	}
+	defer file.Close()
+	var firstnumbers []int
+	var secondnumbers []int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		firstnum, secondnum, ok := parseLocationLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		firstnumbers = append(firstnumbers, firstnum)
+		secondnumbers = append(secondnumbers, secondnum)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Println("Error reading file:", err)
+	}
+
+	lists := NewIncrementalLists(firstnumbers, secondnumbers)
+	lists.printState("initial")
+
+	opsFile, err := os.Open(opsPath)
+	if err != nil {
+		fmt.Println("Error opening operations:", err)
+		return
+	}
+	defer opsFile.Close()
+	scanner = bufio.NewScanner(opsFile)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		operation, err := parseOperation(line)
+		if err != nil {
+			fmt.Println("Error reading operation:", err)
+			return
+		}
+		if err := lists.Apply(operation); err != nil {
+			fmt.Println(operation, "skipped:", err)
+			continue
+		}
+		lists.printState(operation.String())
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Println("Error reading operations:", err)
+	}
+	if distance, ok := lists.Distance(); ok {
+		fmt.Println("The distance is:", distance)
+	}
+	fmt.Println("The similarity is:", lists.Similarity())
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
+)
+
+// With lists gathered by more than two groups, the input has one column per
+// group. The matrix mode computes the part 1 distance and the part 2
+// similarity for every pair of columns. Both matrices are symmetric: the
+// similarity adds up ID * occurrences in one list * occurrences in the other.
+// The similarity diagonal compares a list with itself.
+
+// parseLocationColumns reads every ID on a line of the input.
+func parseLocationColumns(line string) ([]int, bool) {
+	tokens := strings.Fields(line)
+	if len(tokens) == 0 {
+		return nil, false
+	}
+	values := make([]int, len(tokens))
+	for i, token := range tokens {
+		value, err := strconv.Atoi(token)
+		if err != nil {
+			fmt.Println("Error converting string to int")
+			return nil, false
+		}
+		values[i] = value
+	}
+	return values, true
+}
+
+func columnDistance(first, second []int) int {
+	// both columns are sorted
+	distance := 0
+	for i := 0; i < len(first) && i < len(second); i++ {
+		distance += int(math.Abs(float64(first[i] - second[i])))
+	}
+	return distance
+}
+
+func columnSimilarity(first []int, counts map[int]int) int {
+	similarity := 0
+	for _, value := range first {
+		similarity += value * counts[value]
+	}
+	return similarity
+}
+
+func printMatrix(title string, matrix [][]int) {
+	width := len("col") + len(strconv.Itoa(len(matrix)))
+	for _, row := range matrix {
+		for _, value := range row {
+			width = max(width, len(strconv.Itoa(value)))
+		}
+	}
+	fmt.Println(title)
+	fmt.Print(strings.Repeat(" ", width))
+	for j := range matrix {
+		fmt.Printf(" %*s", width, fmt.Sprintf("col%d", j+1))
+	}
+	fmt.Println()
+	for i, row := range matrix {
+		fmt.Printf("%-*s", width, fmt.Sprintf("col%d", i+1))
+		for _, value := range row {
+			fmt.Printf(" %*d", width, value)
+		}
+		fmt.Println()
+	}
+}
+
+func printMatrices() {
+	file, err := input.Open("input.txt")
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return
+	}
+	defer file.Close()
+
+	var columns [][]int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		values, ok := parseLocationColumns(scanner.Text())
+		if !ok {
+			continue
+		}
+		if columns == nil {
+			// the first line decides how many lists there are
+			columns = make([][]int, len(values))
+		}
+		if len(values) != len(columns) {
+			fmt.Println("Expected", len(columns), "columns but found", len(values), "on line:", scanner.Text())
+			continue
+		}
+		for i, value := range values {
+			columns[i] = append(columns[i], value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Println("Error reading file:", err)
+	}
+
+	counts := make([]map[int]int, len(columns))
+	for i, column := range columns {
+		sort.Ints(column)
+		counts[i] = make(map[int]int)
+		for _, value := range column {
+			counts[i][value]++
+		}
+	}
+	distances := make([][]int, len(columns))
+	similarities := make([][]int, len(columns))
+	for i := range columns {
+		distances[i] = make([]int, len(columns))
+		similarities[i] = make([]int, len(columns))
+		for j := range columns {
+			distances[i][j] = columnDistance(columns[i], columns[j])
+			similarities[i][j] = columnSimilarity(columns[i], counts[j])
+		}
+	}
+	printMatrix("The distance matrix is:", distances)
+	fmt.Println()
+	printMatrix("The similarity matrix is:", similarities)
+}
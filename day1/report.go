@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
+)
+
+// The pairing report shows where the day 1 answers come from: every sorted pair
+// with its distance, the pairs that contribute the most, and for part 2 how
+// often each ID occurs in both lists.
+
+type PairEntry struct {
+	Index      int `json:"index"`
+	Left       int `json:"left"`
+	Right      int `json:"right"`
+	Distance   int `json:"distance"`
+	Cumulative int `json:"cumulative"`
+}
+
+type SimilarityEntry struct {
+	ID         int `json:"id"`
+	LeftCount  int `json:"left_count"`
+	RightCount int `json:"right_count"`
+	Score      int `json:"score"` // ID * LeftCount * RightCount
+	Cumulative int `json:"cumulative"`
+}
+
+type Report struct {
+	Distance         int               `json:"distance"`
+	Pairs            []PairEntry       `json:"pairs"`
+	TopContributors  []PairEntry       `json:"top_contributors"`
+	Similarity       int               `json:"similarity"`
+	SimilarityScores []SimilarityEntry `json:"similarity_scores"`
+}
+
+func buildReport(firstnumbers, secondnumbers []int, top int) *Report {
+	report := &Report{}
+	sort.Ints(firstnumbers)
+	sort.Ints(secondnumbers)
+	for i := 0; i < len(firstnumbers) && i < len(secondnumbers); i++ {
+		distance := int(math.Abs(float64(firstnumbers[i] - secondnumbers[i])))
+		report.Distance += distance
+		report.Pairs = append(report.Pairs, PairEntry{
+			Index:      i,
+			Left:       firstnumbers[i],
+			Right:      secondnumbers[i],
+			Distance:   distance,
+			Cumulative: report.Distance,
+		})
+	}
+	report.TopContributors = append([]PairEntry{}, report.Pairs...)
+	sort.SliceStable(report.TopContributors, func(i, j int) bool {
+		return report.TopContributors[i].Distance > report.TopContributors[j].Distance
+	})
+	report.TopContributors = report.TopContributors[:max(0, min(top, len(report.TopContributors)))]
+
+	// part 2 scores every occurrence on the left, so repeated IDs count repeatedly
+	leftCounts := make(map[int]int)
+	for _, id := range firstnumbers {
+		leftCounts[id]++
+	}
+	counts := make(map[int]int)
+	for _, id := range secondnumbers {
+		counts[id]++
+	}
+	ids := make([]int, 0, len(leftCounts))
+	for id := range leftCounts {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		score := id * leftCounts[id] * counts[id]
+		report.Similarity += score
+		report.SimilarityScores = append(report.SimilarityScores, SimilarityEntry{
+			ID:         id,
+			LeftCount:  leftCounts[id],
+			RightCount: counts[id],
+			Score:      score,
+			Cumulative: report.Similarity,
+		})
+	}
+	return report
+}
+
+// writeReportCSV writes the report as one CSV stream. The first column names
+// the section (pair, top, similarity, total) and each section starts with its
+// own header row.
+func writeReportCSV(w io.Writer, report *Report) error {
+	writer := csv.NewWriter(w)
+	itoa := strconv.Itoa
+	writer.Write([]string{"section", "index", "left", "right", "distance", "cumulative"})
+	for _, pair := range report.Pairs {
+		writer.Write([]string{"pair", itoa(pair.Index), itoa(pair.Left), itoa(pair.Right), itoa(pair.Distance), itoa(pair.Cumulative)})
+	}
+	writer.Write([]string{"section", "rank", "left", "right", "distance", "index"})
+	for rank, pair := range report.TopContributors {
+		writer.Write([]string{"top", itoa(rank + 1), itoa(pair.Left), itoa(pair.Right), itoa(pair.Distance), itoa(pair.Index)})
+	}
+	writer.Write([]string{"section", "id", "left_count", "right_count", "score", "cumulative"})
+	for _, entry := range report.SimilarityScores {
+		writer.Write([]string{"similarity", itoa(entry.ID), itoa(entry.LeftCount), itoa(entry.RightCount), itoa(entry.Score), itoa(entry.Cumulative)})
+	}
+	writer.Write([]string{"section", "name", "value"})
+	writer.Write([]string{"total", "distance", itoa(report.Distance)})
+	writer.Write([]string{"total", "similarity", itoa(report.Similarity)})
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeReportJSON(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func printReport(format string, top int) {
+	if format != "csv" && format != "json" {
+		fmt.Println("Unknown report format:", format)
+		return
+	}
+	file, err := input.Open("input.txt")
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return
+	}
+	defer file.Close()
+
+	var firstnumbers []int
+	var secondnumbers []int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		firstnum, secondnum, ok := parseLocationLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		firstnumbers = append(firstnumbers, firstnum)
+		secondnumbers = append(secondnumbers, secondnum)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Println("Error reading file:", err)
+	}
+
+	report := buildReport(firstnumbers, secondnumbers, top)
+	if format == "csv" {
+		err = writeReportCSV(os.Stdout, report)
+	} else {
+		err = writeReportJSON(os.Stdout, report)
+	}
+	if err != nil {
+		fmt.Println("Error writing report:", err)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestBuildReportTop(t *testing.T) {
+	first := []int{3, 4, 2, 1, 3, 3}
+	second := []int{4, 3, 5, 3, 9, 3}
+	for _, test := range []struct {
+		top, want int
+	}{
+		{-1, 0},
+		{0, 0},
+		{2, 2},
+		{100, 6},
+	} {
+		report := buildReport(append([]int{}, first...), append([]int{}, second...), test.top)
+		if len(report.TopContributors) != test.want {
+			t.Errorf("top %d: got %d contributors, want %d", test.top, len(report.TopContributors), test.want)
+		}
+		if report.Distance != 11 || report.Similarity != 31 {
+			t.Errorf("top %d: got distance %d and similarity %d, want 11 and 31", test.top, report.Distance, report.Similarity)
+		}
+	}
+}
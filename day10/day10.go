@@ -3,9 +3,10 @@ package main
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"strconv"
 	"time"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
 func timer(name string) func() {
@@ -70,7 +71,7 @@ func dfs(grid [][]Location, x, y int, visited map[string]bool, result *int) {
 func part1() {
 	// https://adventofcode.com/2024/day/10
 	// Find the number of trailhead to peaks
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
@@ -110,7 +111,7 @@ func part1() {
 func part2() {
 	// https://adventofcode.com/2024/day/10#part2
 	// Find number of distinct paths to the same destination
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
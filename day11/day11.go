@@ -2,11 +2,14 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/answer"
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
 func timer(name string) func() {
@@ -17,10 +20,15 @@ func timer(name string) func() {
 }
 
 type Stone struct {
-	aggregates []int // counts of stones after each blink
+	aggregates []answer.Int // counts of stones after each blink
 }
 
+// Set by --bigint to keep the stone counts in an answer.Int backed by math/big.
+var useBigInt bool
+
 func main() {
+	flag.BoolVar(&useBigInt, "bigint", false, "use arbitrary-precision integers for the stone counts")
+	flag.Parse()
 	part1()
 	part2()
 }
@@ -51,8 +59,8 @@ func blink(stones map[int]*Stone, currentStone int, stepsRemaining int) *Stone {
 	stone, exists := stones[currentStone]
 	if !exists {
 		// we haven't seen this stone before
-		stone = &Stone{aggregates: make([]int, 0)}
-		(*stone).aggregates = append((*stone).aggregates, 1) // self at 0-index
+		stone = &Stone{aggregates: make([]answer.Int, 0)}
+		(*stone).aggregates = append((*stone).aggregates, answer.New(1, useBigInt)) // self at 0-index
 		stones[currentStone] = stone
 	}
 	if len((*stone).aggregates) > stepsRemaining {
@@ -61,12 +69,15 @@ func blink(stones map[int]*Stone, currentStone int, stepsRemaining int) *Stone {
 		return stone
 	}
 	nextNumbers := getNextNumbers(currentStone)
-	newAggregates := make([]int, stepsRemaining+1)
-	newAggregates[0] = 1 // self at 0-index
+	newAggregates := make([]answer.Int, stepsRemaining+1)
+	for i := range newAggregates {
+		newAggregates[i] = answer.New(0, useBigInt)
+	}
+	newAggregates[0] = answer.New(1, useBigInt) // self at 0-index
 	for _, nextNumber := range nextNumbers {
 		nextStone := blink(stones, nextNumber, stepsRemaining-1)
 		for i := 0; i < stepsRemaining; i++ {
-			newAggregates[i+1] += (*nextStone).aggregates[i]
+			newAggregates[i+1] = newAggregates[i+1].Add((*nextStone).aggregates[i])
 		}
 	}
 	(*stone).aggregates = newAggregates
@@ -76,14 +87,14 @@ func blink(stones map[int]*Stone, currentStone int, stepsRemaining int) *Stone {
 func part1() {
 	// https://adventofcode.com/2024/day/11
 	// Do it 25 times
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
 	}
 	defer file.Close()
 	defer timer("part1")()
-	result := 0
+	result := answer.New(0, useBigInt)
 	// variables specific to this problem
 	stones := make(map[int]*Stone) // keeps track of all the stones we've "encountered"
 	totalSteps := 25
@@ -99,7 +110,7 @@ func part1() {
 
 			stoneNumber, _ := strconv.Atoi(stoneInput)
 			stone := blink(stones, stoneNumber, totalSteps)
-			result += (*stone).aggregates[totalSteps]
+			result = result.Add((*stone).aggregates[totalSteps])
 		}
 		// Only one line today
 		break
@@ -108,21 +119,24 @@ func part1() {
 		fmt.Println("Error reading file: ", err)
 	}
 	// Post file-processing code.
-
+	if err := result.Err(); err != nil {
+		fmt.Println("Error computing result:", err)
+		return
+	}
 	fmt.Println("The final result is: ", result)
 }
 
 func part2() {
 	// https://adventofcode.com/2024/day/11#part2
 	// Do it 75 times.
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
 	}
 	defer file.Close()
 	defer timer("part2")()
-	result := 0
+	result := answer.New(0, useBigInt)
 	// variables specific to this problem
 	stones := make(map[int]*Stone) // keeps track of all the stones we've "encountered"
 	totalSteps := 75
@@ -138,7 +152,7 @@ func part2() {
 
 			stoneNumber, _ := strconv.Atoi(stoneInput)
 			stone := blink(stones, stoneNumber, totalSteps)
-			result += (*stone).aggregates[totalSteps]
+			result = result.Add((*stone).aggregates[totalSteps])
 		}
 		// Only one line today
 		break
@@ -147,6 +161,9 @@ func part2() {
 		fmt.Println("Error reading file: ", err)
 	}
 	// Post file-processing code.
-
+	if err := result.Err(); err != nil {
+		fmt.Println("Error computing result:", err)
+		return
+	}
 	fmt.Println("The final result is: ", result)
 }
@@ -3,8 +3,9 @@ package main
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"time"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
 func timer(name string) func() {
@@ -111,7 +112,7 @@ func explorePlot(plot *Plot, visited map[*Plot]bool, area *int, perimeter *int)
 func part1() {
 	// https://adventofcode.com/2024/day/12
 	// Calculate plot areas and perimeters
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
@@ -188,7 +189,7 @@ func explorePlot2(plot *Plot, visited map[*Plot]bool, area *int, soloCorners *in
 func part2() {
 	// https://adventofcode.com/2024/day/12#part2
 	// Calculate number of sides instead of perimeter (i.e. detect straight lines)
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
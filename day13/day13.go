@@ -2,14 +2,17 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"math"
-	"os"
 	"regexp"
 	"strconv"
 	"time"
 
 	"gonum.org/v1/gonum/mat"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/answer"
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
 func timer(name string) func() {
@@ -19,7 +22,13 @@ func timer(name string) func() {
 	}
 }
 
+// Set by --bigint to solve the equations exactly with math/big instead of with
+// floating point.
+var useBigInt bool
+
 func main() {
+	flag.BoolVar(&useBigInt, "bigint", false, "solve with arbitrary-precision integers")
+	flag.Parse()
 	part1()
 	part2()
 }
@@ -28,7 +37,7 @@ func isWholeNumber(f float64) bool {
 	return f == float64(int(f))
 }
 
-func solveEquations(x1, y1, c1, x2, y2, c2 float64) (int, int) {
+func solveEquations(x1, y1, c1, x2, y2, c2 float64) (int, int, error) {
 	// Solve for A and B in the following equations:
 	// x1 * A + y1 * A = c1
 	// x2 * B + y2 * B = c2
@@ -54,24 +63,70 @@ func solveEquations(x1, y1, c1, x2, y2, c2 float64) (int, int) {
 	} else {
 		fmt.Println("A: ", X.At(0, 0), " B: ", X.At(1, 0))
 	}
-	return int(roundedAPresses), int(roundedBPresses)
+	// float64 to int conversion doesn't fail on large values, it just gives garbage
+	if math.Abs(roundedAPresses) >= math.MaxInt || math.Abs(roundedBPresses) >= math.MaxInt {
+		return 0, 0, answer.ErrOverflow
+	}
+	return int(roundedAPresses), int(roundedBPresses), nil
+}
+
+func solveEquationsExact(x1, y1, c1, x2, y2, c2 answer.Int) (answer.Int, answer.Int) {
+	// Same equations as solveEquations, solved with Cramer's rule:
+	// x1 * A + x2 * B = c1
+	// y1 * A + y2 * B = c2
+	zero := answer.New(0, true)
+	determinant := x1.Mul(y2).Sub(x2.Mul(y1))
+	if determinant.Sign() == 0 {
+		fmt.Println("No unique solution found for this prize.")
+		return zero, zero
+	}
+	aNumerator := c1.Mul(y2).Sub(x2.Mul(c2))
+	bNumerator := x1.Mul(c2).Sub(c1.Mul(y1))
+	if aNumerator.Rem(determinant).Sign() != 0 || bNumerator.Rem(determinant).Sign() != 0 {
+		// button presses are integers
+		fmt.Println("No solution found for this prize.")
+		return zero, zero
+	}
+	aPresses := aNumerator.Quo(determinant)
+	bPresses := bNumerator.Quo(determinant)
+	fmt.Println("A: ", aPresses, " B: ", bPresses)
+	return aPresses, bPresses
+}
+
+// solvePrize solves for the button presses, exactly with --bigint and with
+// floating point otherwise.
+func solvePrize(buttons [][2]answer.Int, c1, c2 answer.Int) (answer.Int, answer.Int, error) {
+	if useBigInt {
+		aPresses, bPresses := solveEquationsExact(buttons[0][0], buttons[0][1], c1, buttons[1][0], buttons[1][1], c2)
+		return aPresses, bPresses, nil
+	}
+	values := make([]float64, 0, 6)
+	for _, value := range []answer.Int{buttons[0][0], buttons[0][1], c1, buttons[1][0], buttons[1][1], c2} {
+		f, err := strconv.ParseFloat(value.String(), 64)
+		if err != nil {
+			return answer.Int{}, answer.Int{}, err
+		}
+		values = append(values, f)
+	}
+	aPresses, bPresses, err := solveEquations(values[0], values[1], values[2], values[3], values[4], values[5])
+	return answer.New(aPresses, false), answer.New(bPresses, false), err
 }
 
 func part1() {
 	// https://adventofcode.com/2024/day/13
 	// Do matrix math to solve two linear equations
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
 	}
 	defer file.Close()
 	defer timer("part1")()
-	result := 0
+	result := answer.New(0, useBigInt)
 	// variables specific to this problem
 	buttonPattern := regexp.MustCompile(`Button (\w): X\+(\d+), Y\+(\d+)`)
 	prizePattern := regexp.MustCompile(`Prize: X=(\d+), Y=(\d+)`)
-	buttons := make([][2]float64, 0)
+	buttons := make([][2]answer.Int, 0)
 
 	// Begin file parsing
 	scanner := bufio.NewScanner(file)
@@ -81,64 +136,73 @@ func part1() {
 		// Day-specific code
 		if buttonPattern.MatchString(line) {
 			matches := buttonPattern.FindStringSubmatch(line)
-			x, err := strconv.ParseFloat(matches[2], 64)
+			x, err := answer.Parse(matches[2], useBigInt)
 			if err != nil {
 				fmt.Println("Error converting X portion:", err)
 				return
 			}
-			y, err := strconv.ParseFloat(matches[3], 64)
+			y, err := answer.Parse(matches[3], useBigInt)
 			if err != nil {
 				fmt.Println("Error converting Y portion:", err)
 				return
 			}
-			buttons = append(buttons, [2]float64{x, y})
+			buttons = append(buttons, [2]answer.Int{x, y})
 		} else if prizePattern.MatchString(line) {
 			matches := prizePattern.FindStringSubmatch(line)
-			c1, err := strconv.ParseFloat(matches[1], 64)
+			c1, err := answer.Parse(matches[1], useBigInt)
 			if err != nil {
 				fmt.Println("Error converting X prize:", err)
 				return
 			}
-			c2, err := strconv.ParseFloat(matches[2], 64)
+			c2, err := answer.Parse(matches[2], useBigInt)
 			if err != nil {
 				fmt.Println("Error converting Y prize:", err)
 				return
 			}
 			// Do something with the prize coordinates
-			aPresses, bPresses := solveEquations(buttons[0][0], buttons[0][1], c1, buttons[1][0], buttons[1][1], c2)
+			aPresses, bPresses, err := solvePrize(buttons, c1, c2)
 			// Reset buttons
-			buttons = make([][2]float64, 0)
+			buttons = make([][2]answer.Int, 0)
+			if err != nil {
+				fmt.Println("Error solving for the prize:", err)
+				return
+			}
 
-			if aPresses <= 0 || aPresses > 100 || bPresses <= 0 || bPresses > 100 {
+			limit := answer.New(100, useBigInt)
+			if aPresses.Sign() <= 0 || aPresses.Cmp(limit) > 0 || bPresses.Sign() <= 0 || bPresses.Cmp(limit) > 0 {
 				// No solution, simply continue
 				continue
 			}
 			fmt.Println("A presses: ", aPresses, " B presses: ", bPresses)
-			result += aPresses*3 + bPresses
+			result = result.Add(aPresses.Mul(answer.New(3, useBigInt)).Add(bPresses))
 		}
 	}
 	if err := scanner.Err(); err != nil {
 		fmt.Println("Error reading file: ", err)
 	}
 	// Post file-processing code.
+	if err := result.Err(); err != nil {
+		fmt.Println("Error computing result:", err)
+		return
+	}
 	fmt.Println("The final result is: ", result)
 }
 
 func part2() {
 	// https://adventofcode.com/2024/day/13#part2
 	// Do matrix math to solve two linear equations, with slight modification to conditions
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
 	}
 	defer file.Close()
 	defer timer("part2")()
-	result := 0
+	result := answer.New(0, useBigInt)
 	// variables specific to this problem
 	buttonPattern := regexp.MustCompile(`Button (\w): X\+(\d+), Y\+(\d+)`)
 	prizePattern := regexp.MustCompile(`Prize: X=(\d+), Y=(\d+)`)
-	buttons := make([][2]float64, 0)
+	buttons := make([][2]answer.Int, 0)
 
 	// Begin file parsing
 	scanner := bufio.NewScanner(file)
@@ -148,48 +212,56 @@ func part2() {
 		// Day-specific code
 		if buttonPattern.MatchString(line) {
 			matches := buttonPattern.FindStringSubmatch(line)
-			x, err := strconv.ParseFloat(matches[2], 64)
+			x, err := answer.Parse(matches[2], useBigInt)
 			if err != nil {
 				fmt.Println("Error converting X portion:", err)
 				return
 			}
-			y, err := strconv.ParseFloat(matches[3], 64)
+			y, err := answer.Parse(matches[3], useBigInt)
 			if err != nil {
 				fmt.Println("Error converting Y portion:", err)
 				return
 			}
-			buttons = append(buttons, [2]float64{x, y})
+			buttons = append(buttons, [2]answer.Int{x, y})
 		} else if prizePattern.MatchString(line) {
 			matches := prizePattern.FindStringSubmatch(line)
-			c1, err := strconv.ParseFloat(matches[1], 64)
+			c1, err := answer.Parse(matches[1], useBigInt)
 			if err != nil {
 				fmt.Println("Error converting X prize:", err)
 				return
 			}
-			c2, err := strconv.ParseFloat(matches[2], 64)
+			c2, err := answer.Parse(matches[2], useBigInt)
 			if err != nil {
 				fmt.Println("Error converting Y prize:", err)
 				return
 			}
 			// Part 2 increases the c1 and c2 values by 10000000000000
-			c1 += 10000000000000.0
-			c2 += 10000000000000.0
+			c1 = c1.Add(answer.New(10000000000000, useBigInt))
+			c2 = c2.Add(answer.New(10000000000000, useBigInt))
 			// Do something with the prize coordinates
-			aPresses, bPresses := solveEquations(buttons[0][0], buttons[0][1], c1, buttons[1][0], buttons[1][1], c2)
+			aPresses, bPresses, err := solvePrize(buttons, c1, c2)
 			// Reset buttons
-			buttons = make([][2]float64, 0)
+			buttons = make([][2]answer.Int, 0)
+			if err != nil {
+				fmt.Println("Error solving for the prize:", err)
+				return
+			}
 
-			if aPresses <= 0 || bPresses <= 0 {
+			if aPresses.Sign() <= 0 || bPresses.Sign() <= 0 {
 				// No solution, simply continue
 				continue
 			}
 			fmt.Println("A presses: ", aPresses, " B presses: ", bPresses)
-			result += aPresses*3 + bPresses
+			result = result.Add(aPresses.Mul(answer.New(3, useBigInt)).Add(bPresses))
 		}
 	}
 	if err := scanner.Err(); err != nil {
 		fmt.Println("Error reading file: ", err)
 	}
 	// Post file-processing code.
+	if err := result.Err(); err != nil {
+		fmt.Println("Error computing result:", err)
+		return
+	}
 	fmt.Println("The final result is: ", result)
 }
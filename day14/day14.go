@@ -4,10 +4,11 @@ import (
 	"bufio"
 	"fmt"
 	"math"
-	"os"
 	"regexp"
 	"strconv"
 	"time"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
 func timer(name string) func() {
@@ -73,7 +74,7 @@ func calculateSafetyFactor(robots []*Robot, gridSizeX, gridSizeY int) int {
 func part1() {
 	// https://adventofcode.com/2024/day/14
 	// Calculate positions of all robots after 100 steps and calculate the safety factor
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
@@ -142,7 +143,7 @@ func deepCopyGrid(robots []*Robot) []*Robot {
 func part2() {
 	// https://adventofcode.com/2024/day/14#part2
 	// Find the Christmas Tree easter egg. Do it by minimizing the safety factor.
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
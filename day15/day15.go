@@ -3,9 +3,10 @@ package main
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"regexp"
 	"time"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
 func timer(name string) func() {
@@ -123,7 +124,7 @@ func calculateScore(grid [][]*Position) int {
 func part1() {
 	// https://adventofcode.com/2024/day/15
 	// Move boxes around using a robot
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
@@ -269,7 +270,7 @@ func part2() {
 	// https://adventofcode.com/2024/day/15#part2
 	// Move boxes around using a robot on a grid where everything is twice as wide
 	// Have to account for moving multiple boxes at once
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
@@ -5,8 +5,9 @@ import (
 	"container/heap"
 	"fmt"
 	"math"
-	"os"
 	"time"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
 type Node struct {
@@ -266,7 +267,7 @@ func findShortestPath(grid [][]*Node, pq PriorityQueue, visited map[string]bool,
 func part1() {
 	// https://adventofcode.com/2024/day/16
 	// Find shortest path through maze - Dijsktra's algorithm
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
@@ -321,7 +322,7 @@ func part1() {
 func part2() {
 	// https://adventofcode.com/2024/day/16#part2
 	// Find all the shortest paths through the maze, and get the locations
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
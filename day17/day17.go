@@ -3,11 +3,12 @@ package main
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
 func timer(name string) func() {
@@ -97,7 +98,7 @@ func processInstruction(instructions []string, i int) (int, string) {
 func part1() {
 	// https://adventofcode.com/2024/day/17
 	//
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
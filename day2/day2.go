@@ -2,54 +2,74 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
-	"math"
-	"os"
 	"strconv"
 	"strings"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
+// How many levels the Problem Dampener may remove in part2, set by --dampen.
+var maxRemovals int
+
+// The safety rules, loaded from --policy.
+var policy = DefaultPolicy()
+
 func main() {
+	flag.IntVar(&maxRemovals, "dampen", 1, "maximum number of levels the Problem Dampener may remove")
+	policyPath := flag.String("policy", "", "file with the safety policy, the puzzle's rules by default")
+	diagnostics := flag.String("diagnose", "", "explain every report instead of counting: table or json")
+	workers := flag.Int("workers", 0, "answer both parts in one pass over the file with this many workers, 0 to run the parts one after the other")
+	flag.Parse()
+	if maxRemovals < 0 {
+		fmt.Println("The number of levels to remove can't be negative:", maxRemovals)
+		return
+	}
+	if *policyPath != "" {
+		var err error
+		if policy, err = LoadPolicy(*policyPath); err != nil {
+			fmt.Println("Error loading policy:", err)
+			return
+		}
+	}
+	if *diagnostics != "" {
+		printDiagnostics(*diagnostics)
+		return
+	}
+	if *workers < 0 {
+		fmt.Println("The number of workers can't be negative:", *workers)
+		return
+	}
+	if *workers > 0 {
+		runStreaming(*workers)
+		return
+	}
 	part1()
 	part2()
 }
 
 func isSafeReport(levels []int) bool {
-	// assumes there are no negative numbers for levels
-	previousLevel := -1
-	direction := 0
-	for _, level := range levels {
-		if previousLevel < 0 {
-			previousLevel = level
-			continue
-		}
-		if level == previousLevel {
-			// same level, so cannot be increasing or decreasing
-			return false
-		}
-		var currentDirection int
-		if level > previousLevel {
-			currentDirection = 1
-		} else if level < previousLevel {
-			currentDirection = -1
-		}
-		if direction != 0 && currentDirection != direction {
-			return false
+	// The report is safe if every step is safe going in one of the allowed directions.
+	for _, direction := range policy.directions() {
+		safe := true
+		for i := 1; i < len(levels); i++ {
+			if !policy.isSafeStep(levels[i-1], levels[i], direction) {
+				safe = false
+				break
+			}
 		}
-		// Direction check is good. Now check to see if they differ between 1-3 levels.
-		if math.Abs(float64(level-previousLevel)) > 3 {
-			return false
+		if safe {
+			return true
 		}
-		direction = currentDirection
-		previousLevel = level
 	}
-	return true
+	return false
 }
 
 func part1() {
 	// https://adventofcode.com/2024/day/2
 	// Calculate the number of "safe" levels. A level is safe if the levels are either increasing or decreasing by 1-3 levels.
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
@@ -81,32 +101,61 @@ func part1() {
 	fmt.Println("The # of safe reports is: ", numSafeReports)
 }
 
-func safeAfterDampening(levels []int) bool {
-	for i := 0; i < len(levels); i++ {
-		dampenedLevels := make([]int, 0)
-		if i > 0 {
-			dampenedLevels = append(dampenedLevels, levels[:i]...)
-		}
-		if i < len(levels)-1 {
-			dampenedLevels = append(dampenedLevels, levels[i+1:]...)
-		}
-		if isSafeReport(dampenedLevels) {
-			return true
+// minimumRemovals returns the fewest levels the Problem Dampener has to remove
+// to make the report safe, or -1 if it takes more than maxRemovals.
+//
+// removals[i][d] is the fewest removals that make levels[:i+1] safe while
+// keeping level i, going in direction d. Two kept levels can have at most
+// maxRemovals levels between them, so each entry looks back at most
+// maxRemovals+1 levels, which makes this O(n * maxRemovals).
+func minimumRemovals(levels []int, maxRemovals int) int {
+	n := len(levels)
+	if n <= 1 {
+		return 0
+	}
+	directions := policy.directions()
+	removals := make([][2]int, n)
+	best := -1
+	for i := range levels {
+		for d, direction := range directions {
+			removals[i][d] = -1
+			if i <= maxRemovals {
+				// keep level i as the first level, removing everything before it
+				removals[i][d] = i
+			}
+			for j := max(0, i-maxRemovals-1); j < i; j++ {
+				if removals[j][d] < 0 || !policy.isSafeStep(levels[j], levels[i], direction) {
+					continue
+				}
+				candidate := removals[j][d] + (i - j - 1)
+				if candidate <= maxRemovals && (removals[i][d] < 0 || candidate < removals[i][d]) {
+					removals[i][d] = candidate
+				}
+			}
+			if removals[i][d] < 0 {
+				continue
+			}
+			// keep level i as the last level, removing everything after it
+			total := removals[i][d] + (n - 1 - i)
+			if total <= maxRemovals && (best < 0 || total < best) {
+				best = total
+			}
 		}
 	}
-	return false
+	return best
 }
 
 func part2() {
 	// https://adventofcode.com/2024/day/2
 	// Calculate the number of "safe" levels. A level is safe if the levels are either increasing or decreasing by 1-3 levels.
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
 	}
 	defer file.Close()
 	numSafeReports := 0
+	reportsByRemovals := make(map[int]int) // -1 for reports that can't be made safe
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -122,16 +171,17 @@ func part2() {
 			}
 			levels = append(levels, level)
 		}
-		if isSafeReport(levels) {
+		removals := minimumRemovals(levels, maxRemovals)
+		if removals >= 0 {
 			numSafeReports++
-		} else {
-			if safeAfterDampening(levels) {
-				numSafeReports++
-			}
 		}
+		reportsByRemovals[removals]++
 	}
 	if err := scanner.Err(); err != nil {
 		fmt.Println("Error reading file: ", err)
 	}
+	for removals := 0; removals <= maxRemovals; removals++ {
+		fmt.Println("Reports made safe by removing", removals, "levels:", reportsByRemovals[removals])
+	}
 	fmt.Println("The # of safe reports is: ", numSafeReports)
 }
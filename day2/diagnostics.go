@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
+)
+
+// Diagnostics explain each report instead of only counting it: which level
+// first breaks which rule, and which single-level removals would make the
+// report safe.
+
+const (
+	ruleDirectionChange     = "direction change"
+	ruleDirectionNotAllowed = "direction not allowed"
+	rulePlateau             = "plateau"
+	ruleStepTooLarge        = "step too large"
+	ruleStepTooSmall        = "step too small"
+)
+
+type Violation struct {
+	Index int    `json:"index"` // the level that broke the rule, counting from 0
+	Rule  string `json:"rule"`
+}
+
+type ReportDiagnostic struct {
+	Line           int        `json:"line"`
+	Levels         []int      `json:"levels"`
+	Safe           bool       `json:"safe"`
+	Violation      *Violation `json:"violation,omitempty"`
+	FixingRemovals []int      `json:"fixing_removals"` // indexes of levels whose removal makes the report safe
+}
+
+// firstViolation walks the report the way it reads: the first step that isn't
+// a plateau sets the direction, and the first level that breaks a rule is
+// reported. It returns nil exactly when isSafeReport is true.
+func firstViolation(levels []int) *Violation {
+	direction := 0
+	for i := 1; i < len(levels); i++ {
+		step := levels[i] - levels[i-1]
+		if step == 0 {
+			if !policy.AllowPlateau {
+				return &Violation{Index: i, Rule: rulePlateau}
+			}
+			continue
+		}
+		currentDirection := 1
+		if step < 0 {
+			currentDirection = -1
+			step = -step
+		}
+		if direction == 0 {
+			if (currentDirection == 1 && !policy.Increasing) || (currentDirection == -1 && !policy.Decreasing) {
+				return &Violation{Index: i, Rule: ruleDirectionNotAllowed}
+			}
+			direction = currentDirection
+		} else if currentDirection != direction {
+			return &Violation{Index: i, Rule: ruleDirectionChange}
+		}
+		if step > policy.MaxStep {
+			return &Violation{Index: i, Rule: ruleStepTooLarge}
+		}
+		if step < policy.MinStep {
+			return &Violation{Index: i, Rule: ruleStepTooSmall}
+		}
+	}
+	return nil
+}
+
+// fixingRemovals lists every level whose removal alone makes the report safe.
+func fixingRemovals(levels []int) []int {
+	removals := make([]int, 0)
+	dampenedLevels := make([]int, 0, len(levels))
+	for i := range levels {
+		dampenedLevels = append(dampenedLevels[:0], levels[:i]...)
+		dampenedLevels = append(dampenedLevels, levels[i+1:]...)
+		if isSafeReport(dampenedLevels) {
+			removals = append(removals, i)
+		}
+	}
+	return removals
+}
+
+func diagnose(lineNumber int, levels []int) ReportDiagnostic {
+	diagnostic := ReportDiagnostic{Line: lineNumber, Levels: levels, Violation: firstViolation(levels), FixingRemovals: []int{}}
+	diagnostic.Safe = diagnostic.Violation == nil
+	if !diagnostic.Safe {
+		diagnostic.FixingRemovals = fixingRemovals(levels)
+	}
+	return diagnostic
+}
+
+func joinInts(values []int) string {
+	strs := make([]string, len(values))
+	for i, value := range values {
+		strs[i] = strconv.Itoa(value)
+	}
+	return strings.Join(strs, " ")
+}
+
+func printDiagnosticsTable(diagnostics []ReportDiagnostic) {
+	fmt.Printf("%5s  %-4s  %5s  %-21s  %-16s  %s\n", "Line", "Safe", "Index", "Rule", "Fixing removals", "Levels")
+	for _, diagnostic := range diagnostics {
+		safe, index, rule, removals := "yes", "", "", ""
+		if !diagnostic.Safe {
+			safe = "no"
+			index = strconv.Itoa(diagnostic.Violation.Index)
+			rule = diagnostic.Violation.Rule
+			removals = "none"
+			if len(diagnostic.FixingRemovals) > 0 {
+				removals = joinInts(diagnostic.FixingRemovals)
+			}
+		}
+		fmt.Printf("%5d  %-4s  %5s  %-21s  %-16s  %s\n", diagnostic.Line, safe, index, rule, removals, joinInts(diagnostic.Levels))
+	}
+}
+
+func printDiagnostics(format string) {
+	if format != "table" && format != "json" {
+		fmt.Println("Unknown diagnostics format:", format)
+		return
+	}
+	file, err := input.Open("input.txt")
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return
+	}
+	defer file.Close()
+	diagnostics := make([]ReportDiagnostic, 0)
+	lineNumber := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNumber++
+		tokens := strings.Fields(scanner.Text())
+		levels := make([]int, 0)
+		for _, token := range tokens {
+			level, err := strconv.Atoi(token)
+			if err != nil {
+				fmt.Println("Error converting string to int:", err)
+				continue
+			}
+			levels = append(levels, level)
+		}
+		diagnostics = append(diagnostics, diagnose(lineNumber, levels))
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Println("Error reading file: ", err)
+	}
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(diagnostics); err != nil {
+			fmt.Println("Error writing diagnostics:", err)
+		}
+		return
+	}
+	printDiagnosticsTable(diagnostics)
+}
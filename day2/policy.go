@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Policy is the set of rules a report has to follow to be safe. Different
+// reactor types use different policies; the puzzle's rules are DefaultPolicy.
+// A policy file has one "key = value" per line, "#" starts a comment:
+//
+//	min_step = 1
+//	max_step = 3
+//	allow_plateau = false
+//	directions = increasing, decreasing
+type Policy struct {
+	MinStep      int  // smallest allowed change between adjacent levels
+	MaxStep      int  // largest allowed change between adjacent levels
+	AllowPlateau bool // equal adjacent levels are allowed, in either direction
+	Increasing   bool // reports may increase
+	Decreasing   bool // reports may decrease
+}
+
+func DefaultPolicy() Policy {
+	return Policy{MinStep: 1, MaxStep: 3, Increasing: true, Decreasing: true}
+}
+
+func LoadPolicy(path string) (Policy, error) {
+	policy := DefaultPolicy()
+	file, err := os.Open(path)
+	if err != nil {
+		return policy, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return policy, fmt.Errorf("line %d: expected key = value", lineNumber)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch key {
+		case "min_step":
+			policy.MinStep, err = strconv.Atoi(value)
+		case "max_step":
+			policy.MaxStep, err = strconv.Atoi(value)
+		case "allow_plateau":
+			policy.AllowPlateau, err = strconv.ParseBool(value)
+		case "directions":
+			policy.Increasing, policy.Decreasing = false, false
+			for _, direction := range strings.Split(value, ",") {
+				switch strings.TrimSpace(direction) {
+				case "increasing":
+					policy.Increasing = true
+				case "decreasing":
+					policy.Decreasing = true
+				default:
+					err = fmt.Errorf("unknown direction %q", direction)
+				}
+			}
+		default:
+			err = fmt.Errorf("unknown setting %q", key)
+		}
+		if err != nil {
+			return policy, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return policy, err
+	}
+	return policy, policy.validate()
+}
+
+func (policy Policy) validate() error {
+	if policy.MinStep < 1 || policy.MaxStep < policy.MinStep {
+		return fmt.Errorf("steps should satisfy 1 <= min_step <= max_step, got %d and %d (use allow_plateau for equal levels)", policy.MinStep, policy.MaxStep)
+	}
+	if !policy.Increasing && !policy.Decreasing {
+		return fmt.Errorf("at least one direction should be allowed")
+	}
+	return nil
+}
+
+// directions lists the allowed directions, 1 for increasing and -1 for decreasing.
+func (policy Policy) directions() []int {
+	directions := make([]int, 0, 2)
+	if policy.Increasing {
+		directions = append(directions, 1)
+	}
+	if policy.Decreasing {
+		directions = append(directions, -1)
+	}
+	return directions
+}
+
+// isSafeStep checks a step between two kept levels for a report going in the
+// given direction.
+func (policy Policy) isSafeStep(previousLevel, level, direction int) bool {
+	if level == previousLevel {
+		return policy.AllowPlateau
+	}
+	step := (level - previousLevel) * direction
+	return step >= policy.MinStep && step <= policy.MaxStep
+}
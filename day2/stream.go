@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
+)
+
+// The streaming pipeline answers both parts from a single read of the file,
+// for report dumps too large to read twice. One goroutine reads lines into
+// batches, a pool of workers evaluates each report for part 1 and part 2 and
+// keeps its own counts, and the counts are added up at the end. The channel
+// of batches is bounded, so memory stays at a few batches per worker no
+// matter how large the file is.
+
+// Lines per batch, large enough that handing a batch to a worker costs little
+// next to evaluating it.
+const streamBatchSize = 4096
+
+// reportBatch holds the lines of a batch back to back in one buffer.
+type reportBatch struct {
+	data []byte
+	ends []int // end offset of each line in data
+}
+
+type streamCounts struct {
+	safe              int
+	dampenedSafe      int
+	reportsByRemovals map[int]int // -1 for reports that can't be made safe
+}
+
+func (counts *streamCounts) add(other *streamCounts) {
+	counts.safe += other.safe
+	counts.dampenedSafe += other.dampenedSafe
+	for removals, reports := range other.reportsByRemovals {
+		counts.reportsByRemovals[removals] += reports
+	}
+}
+
+func newStreamCounts() *streamCounts {
+	return &streamCounts{reportsByRemovals: make(map[int]int)}
+}
+
+func evaluateBatch(batch *reportBatch, counts *streamCounts, levels []int) []int {
+	start := 0
+	for _, end := range batch.ends {
+		levels = levels[:0]
+		for _, token := range bytes.Fields(batch.data[start:end]) {
+			level, err := strconv.Atoi(string(token))
+			if err != nil {
+				fmt.Println("Error converting string to int:", err)
+				continue
+			}
+			levels = append(levels, level)
+		}
+		start = end
+		if isSafeReport(levels) {
+			counts.safe++
+		}
+		removals := minimumRemovals(levels, maxRemovals)
+		if removals >= 0 {
+			counts.dampenedSafe++
+		}
+		counts.reportsByRemovals[removals]++
+	}
+	return levels
+}
+
+func runStreaming(workers int) {
+	file, err := input.Open("input.txt")
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return
+	}
+	defer file.Close()
+
+	batches := make(chan *reportBatch, 2*workers)
+	// finished batches come back to the reader to be filled again
+	free := sync.Pool{New: func() any { return &reportBatch{} }}
+	results := make(chan *streamCounts, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counts := newStreamCounts()
+			levels := make([]int, 0)
+			for batch := range batches {
+				levels = evaluateBatch(batch, counts, levels)
+				batch.data = batch.data[:0]
+				batch.ends = batch.ends[:0]
+				free.Put(batch)
+			}
+			results <- counts
+		}()
+	}
+
+	batch := free.Get().(*reportBatch)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		batch.data = append(batch.data, scanner.Bytes()...)
+		batch.ends = append(batch.ends, len(batch.data))
+		if len(batch.ends) == streamBatchSize {
+			batches <- batch
+			batch = free.Get().(*reportBatch)
+		}
+	}
+	if len(batch.ends) > 0 {
+		batches <- batch
+	}
+	close(batches)
+	if err := scanner.Err(); err != nil {
+		fmt.Println("Error reading file: ", err)
+	}
+	wg.Wait()
+	close(results)
+
+	total := newStreamCounts()
+	for counts := range results {
+		total.add(counts)
+	}
+	fmt.Println("The # of safe reports is: ", total.safe)
+	for removals := 0; removals <= maxRemovals; removals++ {
+		fmt.Println("Reports made safe by removing", removals, "levels:", total.reportsByRemovals[removals])
+	}
+	fmt.Println("The # of safe reports is: ", total.dampenedSafe)
+}
@@ -1,53 +1,95 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
-	"os"
-	"regexp"
-	"strconv"
+	"io"
+	"strings"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
+// Set by --extensions to also run the add and neg instructions.
+var useExtensions bool
+
+// Bytes read at a time, set by --chunk. 0 reads the whole memory and parses
+// it in one go.
+var chunkSize int
+
 func main() {
+	flag.BoolVar(&useExtensions, "extensions", false, "also run the add(a,b) and neg(a) instructions")
+	flag.IntVar(&chunkSize, "chunk", 64*1024, "bytes of memory to scan at a time, 0 to read it all at once")
+	strict := flag.Bool("strict", false, "only accept 1-3 digit operands, as the puzzle describes")
+	flag.IntVar(&grammar.MinDigits, "min-digits", grammar.MinDigits, "fewest digits an operand may have")
+	flag.IntVar(&grammar.MaxDigits, "max-digits", grammar.MaxDigits, "most digits an operand may have, 0 for no limit")
+	flag.BoolVar(&grammar.AllowSpaces, "spaces", false, "allow spaces around the parts of an instruction, as in mul ( 2 , 4 )")
+	nearMisses := flag.Bool("near-misses", false, "list the almost-instructions the grammar rejected and why")
+	trace := flag.Bool("trace", false, "list every part 2 instruction and show the enabled regions of the memory")
+	flag.Parse()
+	if chunkSize < 0 {
+		fmt.Println("The chunk size can't be negative:", chunkSize)
+		return
+	}
+	if *strict {
+		digitsSet := false
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "min-digits" || f.Name == "max-digits" {
+				digitsSet = true
+			}
+		})
+		if digitsSet {
+			fmt.Println("--strict sets the operand digits itself and can't be used with --min-digits or --max-digits")
+			return
+		}
+		grammar.MinDigits, grammar.MaxDigits = StrictGrammar().MinDigits, StrictGrammar().MaxDigits
+	}
+	if grammar.MinDigits < 1 || grammar.MaxDigits < 0 || (grammar.MaxDigits > 0 && grammar.MaxDigits < grammar.MinDigits) {
+		fmt.Println("Operands need at least one digit and max-digits can't be below min-digits:", grammar.digitRange())
+		return
+	}
+	if *nearMisses {
+		printNearMisses()
+		return
+	}
+	if *trace {
+		printTrace()
+		return
+	}
 	part1()
 	part2()
 }
 
-func calculate(line string) int {
-	result := 0
-	regexPattern := regexp.MustCompile(`mul\((\d+),(\d+)\)`)
-	// find all the matches in the string
-	matches := regexPattern.FindAllStringSubmatch(line, -1)
-	for _, match := range matches {
-		// convert the strings to integers
-		first, err1 := strconv.Atoi(match[1])
-		second, err2 := strconv.Atoi(match[2])
-		if err1 != nil || err2 != nil {
-			fmt.Println("Error converting string to int:", err1, err2)
-			continue
+// calculate runs every instruction in the memory the interpreter knows about.
+func calculate(interpreter *Interpreter, memory io.Reader) (int, error) {
+	machine := &Machine{Enabled: true}
+	if chunkSize == 0 {
+		contents, err := io.ReadAll(memory)
+		if err != nil {
+			return 0, err
 		}
-		// multiply the two numbers
-		result += first * second
+		// the same line breaks the stream scanner skips
+		oneline := strings.NewReplacer("\n", "", "\r", "").Replace(string(contents))
+		interpreter.Run(machine, interpreter.Compile(oneline))
+		return machine.Result, nil
 	}
-	return result
+	scanner := NewStreamScanner(interpreter.Arities(), func(instruction Instruction) {
+		interpreter.Execute(machine, instruction)
+	})
+	err := scanner.Scan(memory, chunkSize)
+	return machine.Result, err
 }
 
 func part1() {
 	// https://adventofcode.com/2024/day/3
-	// Regex and look for mul(3,4) style strings
-	file, err := os.Open("input.txt")
+	// Look for mul(3,4) style instructions among the garbage
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
 	}
 	defer file.Close()
-	result := 0
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		result += calculate(line)
-	}
-	if err := scanner.Err(); err != nil {
+	result, err := calculate(newInterpreter(1), file)
+	if err != nil {
 		fmt.Println("Error reading file: ", err)
 	}
 	fmt.Println("The final result is: ", result)
@@ -55,42 +97,16 @@ func part1() {
 
 func part2() {
 	// https://adventofcode.com/2024/day/3#part2
-	// https://adventofcode.com/2024/day/3
-	// Regex and look for mul(3,4) style strings
-	file, err := os.Open("input.txt")
+	// Same as part 1, but do() and don't() turn the mul instructions on and off
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
 	}
 	defer file.Close()
-	doDontPattern := regexp.MustCompile(`(do|don't)\(\)`)
-	scanner := bufio.NewScanner(file)
-	oneline := ""
-	for scanner.Scan() {
-		line := scanner.Text()
-		oneline += line
-	}
-	if err := scanner.Err(); err != nil {
+	result, err := calculate(newInterpreter(2), file)
+	if err != nil {
 		fmt.Println("Error reading file: ", err)
 	}
-	result := 0
-	sections := doDontPattern.FindAllStringIndex(oneline, -1)
-	for i := 0; i < len(sections); i++ {
-		sectionIndex := sections[i]
-		lastSectionIndex := []int{len(oneline), len(oneline)}
-		nextSectionIndex := lastSectionIndex
-		if (i + 1) < len(sections) {
-			nextSectionIndex = sections[i+1]
-		}
-		if i == 0 {
-			// First time, we need to add the first section (enabled by default)
-			result += calculate(oneline[:sectionIndex[0]])
-		}
-		// convert the strings to integers
-		section := oneline[sectionIndex[0]:sectionIndex[1]]
-		if section == "do()" {
-			result += calculate(oneline[(sectionIndex[0] + 4):nextSectionIndex[0]])
-		}
-	}
 	fmt.Println("The final result is: ", result)
 }
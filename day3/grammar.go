@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
+)
+
+// Grammar holds the parts of the instruction syntax that can be tightened or
+// loosened. The default accepts what the original regex did, mul(\d+,\d+);
+// the puzzle itself says operands are 1-3 digits, which is --strict.
+type Grammar struct {
+	MinDigits   int
+	MaxDigits   int  // 0 for no limit
+	AllowSpaces bool // allow spaces and tabs around the name, parentheses and commas
+}
+
+// The grammar used by the lexer, parser and stream scanner, set by --strict,
+// --max-digits and --spaces.
+var grammar = DefaultGrammar()
+
+func DefaultGrammar() Grammar {
+	return Grammar{MinDigits: 1}
+}
+
+func StrictGrammar() Grammar {
+	return Grammar{MinDigits: 1, MaxDigits: 3}
+}
+
+// checkOperand returns why the digits can't be an operand, or "" if they can.
+func (grammar Grammar) checkOperand(digits string) string {
+	if len(digits) < grammar.MinDigits || (grammar.MaxDigits > 0 && len(digits) > grammar.MaxDigits) {
+		return fmt.Sprintf("operand %s has %d digits, allowed %s", digits, len(digits), grammar.digitRange())
+	}
+	return ""
+}
+
+func (grammar Grammar) digitRange() string {
+	if grammar.MaxDigits == 0 {
+		return fmt.Sprintf("%d or more", grammar.MinDigits)
+	}
+	return fmt.Sprintf("%d-%d", grammar.MinDigits, grammar.MaxDigits)
+}
+
+// printNearMisses lists the almost-instructions part 2 rejected and why.
+func printNearMisses() {
+	memory, err := input.ReadFile("input.txt")
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return
+	}
+	// parse the memory as one stream with the line breaks skipped, like the
+	// stream scanner, remembering where each byte was in the file
+	joined := make([]byte, 0, len(memory))
+	fileOffsets := make([]int, 0, len(memory))
+	for offset, b := range memory {
+		if b != '\n' && b != '\r' {
+			joined = append(joined, b)
+			fileOffsets = append(fileOffsets, offset)
+		}
+	}
+	starts := lineStarts(memory)
+	_, nearMisses := parseProgram(Lex(string(joined)), newInterpreter(2).Arities())
+	reasons := make(map[string]int)
+	fmt.Printf("%-9s  %-24s  %s\n", "Line:Col", "Text", "Reason")
+	for _, nearMiss := range nearMisses {
+		line, column := position(starts, fileOffsets[nearMiss.Offset])
+		text := nearMiss.Text
+		if len(text) > 24 {
+			text = text[:21] + "..."
+		}
+		fmt.Printf("%-9s  %-24s  %s\n", fmt.Sprintf("%d:%d", line, column), strings.ReplaceAll(text, "\t", " "), nearMiss.Reason)
+		reasons[nearMiss.Rule]++
+	}
+	total := len(nearMisses)
+	fmt.Println()
+	fmt.Println("The # of near misses is: ", total)
+	rules := make([]string, 0, len(reasons))
+	for rule := range reasons {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		fmt.Printf("%6d  %s\n", reasons[rule], rule)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+// The interpreter runs a Program against a Machine. Every instruction it knows
+// has a handler registered under its name, so new instructions only need a
+// new handler: the lexer and the parser learn about them from Arities.
+
+type Machine struct {
+	Enabled bool // mul only counts while enabled, see do() and don't()
+	Result  int
+}
+
+type Handler struct {
+	Arity   int
+	Execute func(machine *Machine, args []int)
+}
+
+type Interpreter struct {
+	handlers map[string]Handler
+}
+
+func NewInterpreter() *Interpreter {
+	return &Interpreter{handlers: make(map[string]Handler)}
+}
+
+func (interpreter *Interpreter) Register(name string, handler Handler) {
+	interpreter.handlers[name] = handler
+}
+
+// Arities maps every registered instruction to its number of arguments.
+func (interpreter *Interpreter) Arities() map[string]int {
+	arities := make(map[string]int, len(interpreter.handlers))
+	for name, handler := range interpreter.handlers {
+		arities[name] = handler.Arity
+	}
+	return arities
+}
+
+func (interpreter *Interpreter) Compile(memory string) Program {
+	return Parse(Lex(memory), interpreter.Arities())
+}
+
+func (interpreter *Interpreter) Execute(machine *Machine, instruction Instruction) {
+	interpreter.handlers[instruction.Name].Execute(machine, instruction.Args)
+}
+
+func (interpreter *Interpreter) Run(machine *Machine, program Program) {
+	for _, instruction := range program {
+		interpreter.Execute(machine, instruction)
+	}
+}
+
+func registerMul(interpreter *Interpreter) {
+	interpreter.Register("mul", Handler{Arity: 2, Execute: func(machine *Machine, args []int) {
+		if machine.Enabled {
+			machine.Result += args[0] * args[1]
+		}
+	}})
+}
+
+func registerConditionals(interpreter *Interpreter) {
+	interpreter.Register("do", Handler{Arity: 0, Execute: func(machine *Machine, args []int) {
+		machine.Enabled = true
+	}})
+	interpreter.Register("don't", Handler{Arity: 0, Execute: func(machine *Machine, args []int) {
+		machine.Enabled = false
+	}})
+}
+
+// registerExtensions adds instructions that aren't part of the puzzle, set by
+// --extensions: add(a,b) adds a+b to the result and neg(a) subtracts a.
+func registerExtensions(interpreter *Interpreter) {
+	interpreter.Register("add", Handler{Arity: 2, Execute: func(machine *Machine, args []int) {
+		if machine.Enabled {
+			machine.Result += args[0] + args[1]
+		}
+	}})
+	interpreter.Register("neg", Handler{Arity: 1, Execute: func(machine *Machine, args []int) {
+		if machine.Enabled {
+			machine.Result -= args[0]
+		}
+	}})
+}
+
+// newInterpreter builds the interpreter for a part: part 1 only knows mul,
+// part 2 also knows do() and don't().
+func newInterpreter(part int) *Interpreter {
+	interpreter := NewInterpreter()
+	registerMul(interpreter)
+	if part == 2 {
+		registerConditionals(interpreter)
+	}
+	if useExtensions {
+		registerExtensions(interpreter)
+	}
+	return interpreter
+}
@@ -0,0 +1,92 @@
+package main
+
+// The lexer splits corrupted memory into tokens. Anything that can't be part
+// of an instruction becomes a garbage token, so the parser only has to look
+// for the shape name(number,number,...) in the token stream.
+
+type TokenKind int
+
+const (
+	TokenGarbage TokenKind = iota
+	TokenIdent             // letters, underscores and apostrophes, as in don't
+	TokenNumber
+	TokenLParen
+	TokenRParen
+	TokenComma
+	TokenSpace // spaces and tabs, only allowed in instructions by --spaces
+)
+
+func (kind TokenKind) String() string {
+	switch kind {
+	case TokenIdent:
+		return "ident"
+	case TokenNumber:
+		return "number"
+	case TokenLParen:
+		return "("
+	case TokenRParen:
+		return ")"
+	case TokenComma:
+		return ","
+	case TokenSpace:
+		return "space"
+	}
+	return "garbage"
+}
+
+type Token struct {
+	Kind   TokenKind
+	Text   string
+	Offset int // byte offset of the token in the memory
+}
+
+func isIdentByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b == '_' || b == '\''
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// Lex returns the tokens of memory in order.
+func Lex(memory string) []Token {
+	tokens := make([]Token, 0)
+	for i := 0; i < len(memory); {
+		start := i
+		kind := TokenGarbage
+		switch b := memory[i]; {
+		case isIdentByte(b):
+			kind = TokenIdent
+			for i < len(memory) && isIdentByte(memory[i]) {
+				i++
+			}
+		case isDigit(b):
+			kind = TokenNumber
+			for i < len(memory) && isDigit(memory[i]) {
+				i++
+			}
+		case isSpace(b):
+			kind = TokenSpace
+			for i < len(memory) && isSpace(memory[i]) {
+				i++
+			}
+		case b == '(':
+			kind = TokenLParen
+			i++
+		case b == ')':
+			kind = TokenRParen
+			i++
+		case b == ',':
+			kind = TokenComma
+			i++
+		default:
+			i++
+		}
+		tokens = append(tokens, Token{Kind: kind, Text: memory[start:i], Offset: start})
+	}
+	return tokens
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Instruction is one recognised instruction, such as mul(2,4) or do().
+type Instruction struct {
+	Name   string
+	Args   []int
+	Offset int // byte offset of the instruction name in the memory
+	Length int // bytes from the name up to and including the closing parenthesis
+}
+
+// Program is the list of instructions found in the memory, in order.
+type Program []Instruction
+
+func (instruction Instruction) String() string {
+	args := make([]string, len(instruction.Args))
+	for i, arg := range instruction.Args {
+		args[i] = strconv.Itoa(arg)
+	}
+	return instruction.Name + "(" + strings.Join(args, ",") + ")"
+}
+
+// instructionName finds the longest known name the identifier ends with, since
+// garbage letters can run straight into an instruction, as in xmul(2,4).
+func instructionName(ident string, arities map[string]int) (string, bool) {
+	best, found := "", false
+	for name := range arities {
+		if strings.HasSuffix(ident, name) && len(name) > len(best) {
+			best, found = name, true
+		}
+	}
+	return best, found
+}
+
+// NearMiss is text that starts like an instruction, a known name, but isn't
+// one. Reason says what broke it.
+type NearMiss struct {
+	Offset int
+	Text   string // from the name up to and including the token that broke it
+	Rule   string // which part of the grammar was broken, for grouping
+	Reason string
+}
+
+const (
+	missMissingParen   = "missing ("
+	missMissingComma   = "missing , or )"
+	missMissingOperand = "missing operand"
+	missOperandDigits  = "operand digits"
+	missOperandCount   = "operand count"
+)
+
+// parseInstruction tries to read an instruction starting at tokens[i], which
+// must be an identifier. It returns the instruction and the index of the
+// token after it. When the identifier ends with a known name but the rest
+// doesn't follow the grammar, it returns the near miss instead.
+func parseInstruction(tokens []Token, i int, arities map[string]int) (Instruction, int, *NearMiss) {
+	name, ok := instructionName(tokens[i].Text, arities)
+	if !ok {
+		return Instruction{}, 0, nil
+	}
+	instruction := Instruction{Name: name, Args: make([]int, 0), Offset: tokens[i].Offset + len(tokens[i].Text) - len(name)}
+	first := i
+	nearMiss := func(last int, rule, reason string) *NearMiss {
+		var text strings.Builder
+		text.WriteString(name)
+		for _, token := range tokens[first+1 : min(last+1, len(tokens))] {
+			text.WriteString(token.Text)
+		}
+		return &NearMiss{Offset: instruction.Offset, Text: text.String(), Rule: rule, Reason: reason}
+	}
+	found := func(i int) string {
+		if i >= len(tokens) {
+			return "the end of the memory"
+		}
+		return strconv.Quote(tokens[i].Text)
+	}
+	skipSpaces := func(i int) int {
+		for grammar.AllowSpaces && i < len(tokens) && tokens[i].Kind == TokenSpace {
+			i++
+		}
+		return i
+	}
+
+	i = skipSpaces(i + 1)
+	if i >= len(tokens) || tokens[i].Kind != TokenLParen {
+		return Instruction{}, 0, nearMiss(i, missMissingParen, "expected ( after "+name+", found "+found(i))
+	}
+	i = skipSpaces(i + 1)
+	for i >= len(tokens) || tokens[i].Kind != TokenRParen {
+		if len(instruction.Args) > 0 {
+			if i >= len(tokens) || tokens[i].Kind != TokenComma {
+				return Instruction{}, 0, nearMiss(i, missMissingComma, "expected , or ) after an operand, found "+found(i))
+			}
+			i = skipSpaces(i + 1)
+		}
+		if i >= len(tokens) || tokens[i].Kind != TokenNumber {
+			return Instruction{}, 0, nearMiss(i, missMissingOperand, "expected an operand, found "+found(i))
+		}
+		if reason := grammar.checkOperand(tokens[i].Text); reason != "" {
+			return Instruction{}, 0, nearMiss(i, missOperandDigits, reason)
+		}
+		arg, err := strconv.Atoi(tokens[i].Text)
+		if err != nil {
+			return Instruction{}, 0, nearMiss(i, missOperandDigits, "operand is too large")
+		}
+		instruction.Args = append(instruction.Args, arg)
+		i = skipSpaces(i + 1)
+	}
+	if len(instruction.Args) != arities[name] {
+		return Instruction{}, 0, nearMiss(i, missOperandCount, fmt.Sprintf("%s takes %d operands, found %d", name, arities[name], len(instruction.Args)))
+	}
+	instruction.Length = tokens[i].Offset + 1 - instruction.Offset
+	return instruction, i + 1, nil
+}
+
+// parseProgram finds every instruction in the tokens whose name and number of
+// arguments match arities, skipping everything else as garbage, and collects
+// the near misses along the way.
+func parseProgram(tokens []Token, arities map[string]int) (Program, []NearMiss) {
+	program := make(Program, 0)
+	nearMisses := make([]NearMiss, 0)
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].Kind != TokenIdent {
+			continue
+		}
+		instruction, next, nearMiss := parseInstruction(tokens, i, arities)
+		if next == 0 {
+			if nearMiss != nil {
+				nearMisses = append(nearMisses, *nearMiss)
+			}
+			// start again from the next token, which may begin an instruction
+			continue
+		}
+		program = append(program, instruction)
+		i = next - 1
+	}
+	return program, nearMisses
+}
+
+func Parse(tokens []Token, arities map[string]int) Program {
+	program, _ := parseProgram(tokens, arities)
+	return program
+}
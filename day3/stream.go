@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"math"
+)
+
+// StreamScanner recognises the same instructions as Lex and Parse, but one
+// byte at a time, so memory can be read in chunks of any size. Whatever it is
+// in the middle of, the tail of an identifier or half of an argument list,
+// is kept between chunks, and so is the machine's enabled state. It only
+// holds the instruction being read, so its memory doesn't grow with the input.
+//
+// Line breaks are skipped, as if the lines of the dump were joined: they come
+// from how the memory was saved and can fall in the middle of an instruction.
+
+type scanState int
+
+const (
+	scanIdle        scanState = iota // outside an instruction, possibly in an identifier
+	scanOpen                         // after "name(", expecting a number or ")"
+	scanNumber                       // in a number
+	scanComma                        // after a comma, expecting a number
+	scanName                         // after a name and spaces, expecting "("
+	scanAfterNumber                  // after a number and spaces, expecting "," or ")"
+)
+
+type StreamScanner struct {
+	arities  map[string]int
+	maxName  int
+	emit     func(Instruction)
+	state    scanState
+	ident    []byte // the last maxName bytes of the current identifier
+	identAt  []int  // the offset of each byte in ident
+	name     string
+	start    int
+	args     []int
+	number   int
+	digits   int
+	offset   int // offset of the next byte in the memory
+	overflow bool
+}
+
+func NewStreamScanner(arities map[string]int, emit func(Instruction)) *StreamScanner {
+	scanner := &StreamScanner{arities: arities, emit: emit}
+	for name := range arities {
+		scanner.maxName = max(scanner.maxName, len(name))
+	}
+	return scanner
+}
+
+// Write feeds the next chunk of memory to the scanner.
+func (scanner *StreamScanner) Write(chunk []byte) (int, error) {
+	for _, b := range chunk {
+		if b != '\n' && b != '\r' {
+			scanner.feed(b)
+		}
+		scanner.offset++
+	}
+	return len(chunk), nil
+}
+
+// Scan reads all of r in chunks of chunkSize bytes.
+func (scanner *StreamScanner) Scan(r io.Reader, chunkSize int) error {
+	buffer := make([]byte, chunkSize)
+	for {
+		n, err := r.Read(buffer)
+		scanner.Write(buffer[:n])
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (scanner *StreamScanner) resetIdent() {
+	scanner.ident = scanner.ident[:0]
+	scanner.identAt = scanner.identAt[:0]
+}
+
+func (scanner *StreamScanner) feed(b byte) {
+	switch scanner.state {
+	case scanIdle:
+		if isIdentByte(b) {
+			if len(scanner.ident) == scanner.maxName && scanner.maxName > 0 {
+				// only the end of an identifier can be an instruction name
+				copy(scanner.ident, scanner.ident[1:])
+				copy(scanner.identAt, scanner.identAt[1:])
+				scanner.ident = scanner.ident[:len(scanner.ident)-1]
+				scanner.identAt = scanner.identAt[:len(scanner.identAt)-1]
+			}
+			scanner.ident = append(scanner.ident, b)
+			scanner.identAt = append(scanner.identAt, scanner.offset)
+			return
+		}
+		if isSpace(b) && grammar.AllowSpaces && len(scanner.ident) > 0 {
+			// the identifier is over, but "(" may still follow
+			scanner.state = scanName
+			return
+		}
+		scanner.open(b)
+	case scanName:
+		if isSpace(b) {
+			return
+		}
+		if b != '(' {
+			scanner.fail(b)
+			return
+		}
+		scanner.open(b)
+	case scanOpen, scanComma:
+		if isSpace(b) && grammar.AllowSpaces {
+			return
+		}
+		if isDigit(b) {
+			scanner.state = scanNumber
+			scanner.number = int(b - '0')
+			scanner.digits = 1
+			scanner.overflow = false
+			return
+		}
+		if b == ')' && scanner.state == scanOpen && len(scanner.args) == 0 {
+			scanner.finish(b)
+			return
+		}
+		scanner.fail(b)
+	case scanNumber:
+		if isDigit(b) {
+			digit := int(b - '0')
+			if scanner.number > (math.MaxInt-digit)/10 {
+				// too large to be an operand
+				scanner.overflow = true
+			}
+			scanner.number = scanner.number*10 + digit
+			scanner.digits++
+			if grammar.MaxDigits > 0 && scanner.digits > grammar.MaxDigits {
+				scanner.fail(b)
+			}
+			return
+		}
+		if scanner.overflow || scanner.digits < grammar.MinDigits {
+			scanner.fail(b)
+			return
+		}
+		scanner.args = append(scanner.args, scanner.number)
+		if isSpace(b) && grammar.AllowSpaces {
+			scanner.state = scanAfterNumber
+			return
+		}
+		scanner.afterNumber(b)
+	case scanAfterNumber:
+		if isSpace(b) {
+			return
+		}
+		scanner.afterNumber(b)
+	}
+}
+
+// open starts an instruction if b is "(" and the identifier before it ends
+// with a known name.
+func (scanner *StreamScanner) open(b byte) {
+	scanner.state = scanIdle
+	if b == '(' && len(scanner.ident) > 0 {
+		if name, ok := instructionName(string(scanner.ident), scanner.arities); ok {
+			scanner.state = scanOpen
+			scanner.name = name
+			scanner.start = scanner.identAt[len(scanner.identAt)-len(name)]
+			scanner.args = scanner.args[:0]
+		}
+	}
+	scanner.resetIdent()
+}
+
+func (scanner *StreamScanner) afterNumber(b byte) {
+	switch b {
+	case ',':
+		scanner.state = scanComma
+	case ')':
+		scanner.finish(b)
+	default:
+		scanner.fail(b)
+	}
+}
+
+// fail abandons the instruction being read. The byte that broke it may start
+// the next instruction, so it is read again from the idle state.
+func (scanner *StreamScanner) fail(b byte) {
+	scanner.state = scanIdle
+	scanner.resetIdent()
+	scanner.feed(b)
+}
+
+func (scanner *StreamScanner) finish(b byte) {
+	if len(scanner.args) != scanner.arities[scanner.name] {
+		scanner.fail(b)
+		return
+	}
+	scanner.state = scanIdle
+	args := make([]int, len(scanner.args))
+	copy(args, scanner.args)
+	scanner.emit(Instruction{Name: scanner.name, Args: args, Offset: scanner.start, Length: scanner.offset + 1 - scanner.start})
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
+)
+
+// The trace shows how the part 2 answer comes together: every instruction with
+// where it is in the memory, whether mul was enabled at that point and by
+// which do() or don't(), and how much it added. The memory is then printed
+// again with the enabled regions in green and the disabled ones in red.
+
+const (
+	ansiReset = "\033[0m"
+	ansiBold  = "\033[1m"
+	ansiGreen = "\033[32m"
+	ansiRed   = "\033[31m"
+)
+
+type TraceEntry struct {
+	Instruction  Instruction
+	Line, Column int    // 1-based, from the start of the instruction
+	Enabled      bool   // the state when the instruction ran
+	EnabledBy    string // the do() or don't() that set it, with its position
+	Contribution int
+	Total        int
+}
+
+// lineStarts returns the offset at which each line of memory starts.
+func lineStarts(memory []byte) []int {
+	starts := []int{0}
+	for i, b := range memory {
+		if b == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+func position(starts []int, offset int) (int, int) {
+	line := sort.SearchInts(starts, offset+1) - 1
+	return line + 1, offset - starts[line] + 1
+}
+
+func traceMemory(interpreter *Interpreter, memory []byte) []TraceEntry {
+	starts := lineStarts(memory)
+	machine := &Machine{Enabled: true}
+	enabledBy := "start"
+	entries := make([]TraceEntry, 0)
+	scanner := NewStreamScanner(interpreter.Arities(), func(instruction Instruction) {
+		line, column := position(starts, instruction.Offset)
+		entry := TraceEntry{Instruction: instruction, Line: line, Column: column, Enabled: machine.Enabled, EnabledBy: enabledBy}
+		before := machine.Result
+		interpreter.Execute(machine, instruction)
+		entry.Contribution = machine.Result - before
+		entry.Total = machine.Result
+		if instruction.Name == "do" || instruction.Name == "don't" {
+			enabledBy = fmt.Sprintf("%s at %d:%d", instruction, line, column)
+		}
+		entries = append(entries, entry)
+	})
+	scanner.Write(memory)
+	return entries
+}
+
+func printTraceTable(entries []TraceEntry) {
+	fmt.Printf("%8s  %-9s  %-16s  %-7s  %-22s  %12s  %12s\n", "Offset", "Line:Col", "Instruction", "Enabled", "Enabled by", "Contribution", "Total")
+	for _, entry := range entries {
+		enabled := "yes"
+		if !entry.Enabled {
+			enabled = "no"
+		}
+		fmt.Printf("%8d  %-9s  %-16s  %-7s  %-22s  %12d  %12d\n",
+			entry.Instruction.Offset, fmt.Sprintf("%d:%d", entry.Line, entry.Column), entry.Instruction,
+			enabled, entry.EnabledBy, entry.Contribution, entry.Total)
+	}
+}
+
+// renderTrace prints the memory in green where mul is enabled and in red where
+// it isn't, with the recognised instructions in bold.
+func renderTrace(memory []byte, entries []TraceEntry) string {
+	var builder strings.Builder
+	// every line gets its own escape codes, so pagers don't lose the colour
+	write := func(segment []byte, style string) {
+		for i, line := range bytes.Split(segment, []byte("\n")) {
+			if i > 0 {
+				builder.WriteByte('\n')
+			}
+			if len(line) > 0 {
+				builder.WriteString(style)
+				builder.Write(line)
+				builder.WriteString(ansiReset)
+			}
+		}
+	}
+	colour := ansiGreen
+	cursor := 0
+	for _, entry := range entries {
+		instruction := entry.Instruction
+		write(memory[cursor:instruction.Offset], colour)
+		write(memory[instruction.Offset:instruction.Offset+instruction.Length], colour+ansiBold)
+		cursor = instruction.Offset + instruction.Length
+		switch instruction.Name {
+		case "do":
+			colour = ansiGreen
+		case "don't":
+			colour = ansiRed
+		}
+	}
+	write(memory[cursor:], colour)
+	return builder.String()
+}
+
+func printTrace() {
+	memory, err := input.ReadFile("input.txt")
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return
+	}
+	entries := traceMemory(newInterpreter(2), memory)
+	printTraceTable(entries)
+	fmt.Println()
+	fmt.Println(renderTrace(bytes.TrimRight(memory, "\n"), entries))
+	if len(entries) > 0 {
+		fmt.Println("The final result is: ", entries[len(entries)-1].Total)
+	} else {
+		fmt.Println("The final result is: ", 0)
+	}
+}
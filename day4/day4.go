@@ -2,91 +2,97 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
-	"os"
+	"slices"
+	"strings"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
-func main() {
-	part1()
-	part2()
-}
+// The words part1 searches for and the directions it reads in, set by --words
+// and --directions.
+var words = []string{"XMAS"}
+var directions = allDirections
+
+// The shape part2 looks for, set by --template.
+var template Template
 
-func safeGet(grid [][]rune, row, col int) rune {
-	if row < 0 || col < 0 {
-		return ' '
+// Set by --list to print every match the parts find.
+var listMatches bool
+
+// How the parts draw the grid with their matches, set by --render: plain,
+// ansi or heatmap. Empty draws nothing.
+var renderMode string
+
+// Set by --workers and --stripe to search the grid in stripes of rows, in
+// parallel, without reading all of it into memory.
+var workers int
+var stripeRows int
+
+func main() {
+	wordList := flag.String("words", "XMAS", "comma separated words to search for")
+	directionList := flag.String("directions", "all", "comma separated directions to read in (E, W, S, N, SE, SW, NE, NW), or all")
+	templatePattern := flag.String("template", "M.S/.A./M.S", "shape to find in part2, rows separated by / and . for any letter")
+	flag.BoolVar(&listMatches, "list", false, "print every match with where it is")
+	flag.StringVar(&renderMode, "render", "", "draw the grid with the matches: plain, ansi or heatmap")
+	flag.IntVar(&workers, "workers", 0, "search stripes of the grid with this many workers, 0 to search the whole grid at once")
+	flag.IntVar(&stripeRows, "stripe", 256, "rows per stripe with --workers")
+	flag.Parse()
+	if workers < 0 || stripeRows < 1 {
+		fmt.Println("Need at least one row per stripe and a non-negative number of workers")
+		return
 	}
-	if row >= len(grid) || col >= len(grid[0]) {
-		return ' '
+	if workers > 0 && (listMatches || renderMode != "") {
+		// both need the whole grid, which the striped search never holds
+		fmt.Println("--list and --render can't be used with --workers")
+		return
 	}
-	return grid[row][col]
-}
-
-func calculateXmasAllDirections(grid [][]rune) int {
-	result := 0
-	numrows := len(grid)
-	numcols := len(grid[0])
-	for row := 0; row < numrows; row++ {
-		for col := 0; col < numcols; col++ {
-			if grid[row][col] != 'X' {
-				continue
-			}
-			// If character is "X", then check for number of XMAS
-			forward := []rune{'X', safeGet(grid, row, col+1), safeGet(grid, row, col+2), safeGet(grid, row, col+3)}
-			backward := []rune{'X', safeGet(grid, row, col-1), safeGet(grid, row, col-2), safeGet(grid, row, col-3)}
-			up := []rune{'X', safeGet(grid, row-1, col), safeGet(grid, row-2, col), safeGet(grid, row-3, col)}
-			down := []rune{'X', safeGet(grid, row+1, col), safeGet(grid, row+2, col), safeGet(grid, row+3, col)}
-			se_diag := []rune{'X', safeGet(grid, row+1, col+1), safeGet(grid, row+2, col+2), safeGet(grid, row+3, col+3)}
-			sw_diag := []rune{'X', safeGet(grid, row+1, col-1), safeGet(grid, row+2, col-2), safeGet(grid, row+3, col-3)}
-			ne_diag := []rune{'X', safeGet(grid, row-1, col+1), safeGet(grid, row-2, col+2), safeGet(grid, row-3, col+3)}
-			nw_diag := []rune{'X', safeGet(grid, row-1, col-1), safeGet(grid, row-2, col-2), safeGet(grid, row-3, col-3)}
-			for _, direction := range [][]rune{forward, backward, up, down, se_diag, sw_diag, ne_diag, nw_diag} {
-				if string(direction) == "XMAS" {
-					result += 1
-				}
-			}
-		}
+	if renderMode != "" && renderMode != "plain" && renderMode != "ansi" && renderMode != "heatmap" {
+		fmt.Println("Unknown render mode:", renderMode)
+		return
 	}
-	return result
-}
-
-func calculateMasInXFormation(grid [][]rune) int {
-	result := 0
-	numrows := len(grid)
-	numcols := len(grid[0])
-	for row := 0; row < numrows; row++ {
-		for col := 0; col < numcols; col++ {
-			if grid[row][col] != 'A' {
-				continue
-			}
-			// If character is "A", then check for two 3-letter diagonals
-			ne_sw_diag := []rune{safeGet(grid, row-1, col+1), 'A', safeGet(grid, row+1, col-1)}
-			sw_ne_diag := []rune{safeGet(grid, row+1, col-1), 'A', safeGet(grid, row-1, col+1)}
-			nw_se_diag := []rune{safeGet(grid, row-1, col-1), 'A', safeGet(grid, row+1, col+1)}
-			se_nw_diag := []rune{safeGet(grid, row+1, col+1), 'A', safeGet(grid, row-1, col-1)}
-			diag_count := 0
-			if string(ne_sw_diag) == "MAS" || string(sw_ne_diag) == "MAS" {
-				diag_count += 1
-			}
-			if string(nw_se_diag) == "MAS" || string(se_nw_diag) == "MAS" {
-				diag_count += 1
-			}
-			if diag_count == 2 {
-				result += 1
-			}
+	words = words[:0]
+	for _, word := range strings.Split(*wordList, ",") {
+		// a word listed twice would be counted twice
+		if word = strings.TrimSpace(word); word != "" && !slices.Contains(words, word) {
+			words = append(words, word)
 		}
 	}
-	return result
+	if len(words) == 0 {
+		fmt.Println("No words to search for")
+		return
+	}
+	var err error
+	if directions, err = parseDirections(*directionList); err != nil {
+		fmt.Println("Error reading directions:", err)
+		return
+	}
+	if template, err = parseTemplate(*templatePattern); err != nil {
+		fmt.Println("Error reading template:", err)
+		return
+	}
+	part1()
+	part2()
 }
 
 func part1() {
 	// https://adventofcode.com/2024/day/4
-	// Do a word search of all XMAS in the grid
-	file, err := os.Open("input.txt")
+	// Do a word search of all XMAS in the grid, or of the words from --words
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
 	}
 	defer file.Close()
+	if workers > 0 {
+		counts, err := searchWordsStriped(file)
+		if err != nil {
+			fmt.Println("Error reading file: ", err)
+		}
+		printWordCounts(counts)
+		return
+	}
 	var grid [][]rune // stores the 2D array of characters
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
@@ -97,22 +103,51 @@ func part1() {
 		}
 		grid = append(grid, row)
 	}
-	result := calculateXmasAllDirections(grid)
 	if err := scanner.Err(); err != nil {
 		fmt.Println("Error reading file: ", err)
 	}
-	fmt.Println("The final result is: ", result)
+	matches := searchWords(grid, words, directions)
+	counts := make(map[string]int)
+	for _, match := range matches {
+		if listMatches {
+			fmt.Println(match)
+		}
+		counts[match.Word]++
+	}
+	if renderMode != "" {
+		printRender(renderMode, grid, wordCellCounts(grid, matches))
+	}
+	printWordCounts(counts)
+}
+
+func printWordCounts(counts map[string]int) {
+	total := 0
+	for _, word := range words {
+		if len(words) > 1 {
+			fmt.Println(word, "found", counts[word], "times")
+		}
+		total += counts[word]
+	}
+	fmt.Println("The final result is: ", total)
 }
 
 func part2() {
 	// https://adventofcode.com/2024/day/4#part2
-	// Find all MAS in a cross pattern
-	file, err := os.Open("input.txt")
+	// Find all MAS in a cross pattern, or the shape from --template, in any rotation or reflection
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
 	}
 	defer file.Close()
+	if workers > 0 {
+		result, err := matchTemplateStriped(file)
+		if err != nil {
+			fmt.Println("Error reading file: ", err)
+		}
+		fmt.Println("The final result is: ", result)
+		return
+	}
 	var grid [][]rune // stores the 2D array of characters
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
@@ -123,9 +158,17 @@ func part2() {
 		}
 		grid = append(grid, row)
 	}
-	result := calculateMasInXFormation(grid)
 	if err := scanner.Err(); err != nil {
 		fmt.Println("Error reading file: ", err)
 	}
-	fmt.Println("The final result is: ", result)
+	matches := matchTemplate(grid, template)
+	if listMatches {
+		for _, match := range matches {
+			fmt.Println(match)
+		}
+	}
+	if renderMode != "" {
+		printRender(renderMode, grid, templateCellCounts(grid, matches))
+	}
+	fmt.Println("The final result is: ", len(matches))
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The renderers show which letters the matches use, like the illustrations
+// in the puzzle. They all work from a count of matches per cell.
+
+const (
+	ansiReset  = "\033[0m"
+	ansiDim    = "\033[2m"
+	ansiYellow = "\033[1;33m"
+)
+
+func newCellCounts(grid [][]rune) [][]int {
+	counts := make([][]int, len(grid))
+	for row := range grid {
+		counts[row] = make([]int, len(grid[row]))
+	}
+	return counts
+}
+
+// wordCellCounts counts, for every cell, the word matches that use it.
+func wordCellCounts(grid [][]rune, matches []Match) [][]int {
+	counts := newCellCounts(grid)
+	for _, match := range matches {
+		for k := range []rune(match.Word) {
+			counts[match.Row+k*match.Direction.DRow][match.Col+k*match.Direction.DCol]++
+		}
+	}
+	return counts
+}
+
+// templateCellCounts counts, for every cell, the template matches that use
+// it. Wildcard cells aren't part of the shape, so they don't count.
+func templateCellCounts(grid [][]rune, matches []TemplateMatch) [][]int {
+	counts := newCellCounts(grid)
+	for _, match := range matches {
+		for r, templateRow := range match.Variant {
+			for c, letter := range templateRow {
+				if letter != templateWildcard {
+					counts[match.Row+r][match.Col+c]++
+				}
+			}
+		}
+	}
+	return counts
+}
+
+// renderPlain replaces every letter no match uses with '.'.
+func renderPlain(grid [][]rune, counts [][]int) string {
+	var builder strings.Builder
+	for row := range grid {
+		for col, letter := range grid[row] {
+			if counts[row][col] == 0 {
+				letter = '.'
+			}
+			builder.WriteRune(letter)
+		}
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+// renderANSI keeps every letter but dims the ones no match uses.
+func renderANSI(grid [][]rune, counts [][]int) string {
+	var builder strings.Builder
+	for row := range grid {
+		style := ""
+		for col, letter := range grid[row] {
+			cellStyle := ansiDim
+			if counts[row][col] > 0 {
+				cellStyle = ansiYellow
+			}
+			if cellStyle != style {
+				builder.WriteString(ansiReset + cellStyle)
+				style = cellStyle
+			}
+			builder.WriteRune(letter)
+		}
+		builder.WriteString(ansiReset + "\n")
+	}
+	return builder.String()
+}
+
+// renderHeatmap shows how many matches use each cell: '.' for none, 1-9, and
+// '+' for ten or more.
+func renderHeatmap(grid [][]rune, counts [][]int) string {
+	var builder strings.Builder
+	for row := range grid {
+		for col := range grid[row] {
+			switch count := counts[row][col]; {
+			case count == 0:
+				builder.WriteByte('.')
+			case count < 10:
+				builder.WriteByte(byte('0' + count))
+			default:
+				builder.WriteByte('+')
+			}
+		}
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+func printRender(mode string, grid [][]rune, counts [][]int) {
+	switch mode {
+	case "plain":
+		fmt.Print(renderPlain(grid, counts))
+	case "ansi":
+		fmt.Print(renderANSI(grid, counts))
+	case "heatmap":
+		fmt.Print(renderHeatmap(grid, counts))
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The word search engine finds any list of words in the grid. Every direction
+// turns the grid into lines, the rows for E and W, the columns for N and S and
+// the diagonals for the rest, read in that direction. An Aho-Corasick
+// automaton built from the words then finds all of them in one pass over each
+// line, instead of comparing every word at every cell.
+
+type Direction struct {
+	Name       string
+	DRow, DCol int
+}
+
+var allDirections = []Direction{
+	{"E", 0, 1},
+	{"W", 0, -1},
+	{"S", 1, 0},
+	{"N", -1, 0},
+	{"SE", 1, 1},
+	{"SW", 1, -1},
+	{"NE", -1, 1},
+	{"NW", -1, -1},
+}
+
+// parseDirections reads a comma separated list of direction names, or "all".
+func parseDirections(list string) ([]Direction, error) {
+	if list == "all" {
+		return allDirections, nil
+	}
+	directions := make([]Direction, 0)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		found := false
+		for _, direction := range allDirections {
+			if direction.Name == name {
+				directions = append(directions, direction)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown direction %q, expected one of E, W, S, N, SE, SW, NE, NW", name)
+		}
+	}
+	return directions, nil
+}
+
+// Match is one word found in the grid, starting at (Row, Col), counting from 0,
+// and read in Direction. A palindrome is found once in each of the opposite
+// directions, as in the puzzle.
+type Match struct {
+	Word      string
+	Row, Col  int
+	Direction Direction
+}
+
+func (match Match) String() string {
+	return fmt.Sprintf("%s at (%d, %d) going %s", match.Word, match.Row, match.Col, match.Direction.Name)
+}
+
+// automaton is an Aho-Corasick automaton over runes. State 0 is the root.
+type automaton struct {
+	next    []map[rune]int
+	fail    []int
+	outputs [][]int // indexes of the words that end in each state
+}
+
+func newAutomaton(words []string) *automaton {
+	ac := &automaton{next: []map[rune]int{{}}, fail: []int{0}, outputs: [][]int{nil}}
+	for index, word := range words {
+		state := 0
+		for _, r := range word {
+			child, ok := ac.next[state][r]
+			if !ok {
+				child = len(ac.next)
+				ac.next = append(ac.next, map[rune]int{})
+				ac.fail = append(ac.fail, 0)
+				ac.outputs = append(ac.outputs, nil)
+				ac.next[state][r] = child
+			}
+			state = child
+		}
+		ac.outputs[state] = append(ac.outputs[state], index)
+	}
+	// breadth first, so the failure state of a parent is known before its children
+	queue := make([]int, 0)
+	for _, child := range ac.next[0] {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for r, child := range ac.next[state] {
+			fail := ac.fail[state]
+			for fail != 0 {
+				if _, ok := ac.next[fail][r]; ok {
+					break
+				}
+				fail = ac.fail[fail]
+			}
+			if target, ok := ac.next[fail][r]; ok && target != child {
+				ac.fail[child] = target
+			}
+			ac.outputs[child] = append(ac.outputs[child], ac.outputs[ac.fail[child]]...)
+			queue = append(queue, child)
+		}
+	}
+	return ac
+}
+
+func (ac *automaton) step(state int, r rune) int {
+	for {
+		if child, ok := ac.next[state][r]; ok {
+			return child
+		}
+		if state == 0 {
+			return 0
+		}
+		state = ac.fail[state]
+	}
+}
+
+func inGrid(grid [][]rune, row, col int) bool {
+	return row >= 0 && col >= 0 && row < len(grid) && col < len(grid[row])
+}
+
+// searchWords returns every occurrence of the words in the grid, reading in
+// the given directions.
+func searchWords(grid [][]rune, words []string, directions []Direction) []Match {
+	matches := make([]Match, 0)
+	ac := newAutomaton(words)
+	lengths := make([]int, len(words))
+	for i, word := range words {
+		lengths[i] = len([]rune(word))
+	}
+	for _, direction := range directions {
+		for row := range grid {
+			for col := range grid[row] {
+				// each line starts at a cell whose predecessor is outside the grid
+				if inGrid(grid, row-direction.DRow, col-direction.DCol) {
+					continue
+				}
+				state := 0
+				position := 0
+				for r, c := row, col; inGrid(grid, r, c); r, c = r+direction.DRow, c+direction.DCol {
+					state = ac.step(state, grid[r][c])
+					for _, index := range ac.outputs[state] {
+						back := position - lengths[index] + 1
+						matches = append(matches, Match{
+							Word:      words[index],
+							Row:       row + back*direction.DRow,
+							Col:       col + back*direction.DCol,
+							Direction: direction,
+						})
+					}
+					position++
+				}
+			}
+		}
+	}
+	return matches
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+// The striped search is for grids too large to hold in memory. The rows are
+// read a stripe at a time and every stripe is searched by one of a pool of
+// workers. A stripe also carries the first rows of the next one, as many as
+// a match can reach past its top row, so a match crossing the seam is found
+// whole. Such a match is then found by both stripes, so each stripe only
+// counts the matches whose top row it owns.
+
+type stripe struct {
+	start int      // the grid row of rows[0]
+	rows  [][]rune // the owned rows, then the overlap
+	owned int
+}
+
+// readStripes sends the grid in stripes of stripeRows rows plus overlap rows
+// of the next stripe.
+func readStripes(r io.Reader, stripeRows, overlap int, stripes chan<- stripe) error {
+	defer close(stripes)
+	buffer := make([][]rune, 0, stripeRows+overlap)
+	start := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		buffer = append(buffer, []rune(scanner.Text()))
+		if len(buffer) == stripeRows+overlap {
+			stripes <- stripe{start: start, rows: buffer, owned: stripeRows}
+			next := make([][]rune, overlap, stripeRows+overlap)
+			copy(next, buffer[stripeRows:])
+			buffer = next
+			start += stripeRows
+		}
+	}
+	if len(buffer) > 0 {
+		// the last stripe has nothing below it to overlap
+		stripes <- stripe{start: start, rows: buffer, owned: len(buffer)}
+	}
+	return scanner.Err()
+}
+
+// searchStriped runs search on every stripe across workers goroutines and adds
+// up the counts it returns.
+func searchStriped(r io.Reader, overlap int, search func(stripe) map[string]int) (map[string]int, error) {
+	stripes := make(chan stripe, workers)
+	results := make(chan map[string]int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for stripe := range stripes {
+				results <- search(stripe)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- readStripes(r, stripeRows, overlap, stripes)
+	}()
+	total := make(map[string]int)
+	for counts := range results {
+		for key, count := range counts {
+			total[key] += count
+		}
+	}
+	return total, <-errs
+}
+
+// searchWordsStriped counts the matches of every word, like searchWords.
+func searchWordsStriped(r io.Reader) (map[string]int, error) {
+	overlap := 0
+	for _, word := range words {
+		overlap = max(overlap, len([]rune(word))-1)
+	}
+	return searchStriped(r, overlap, func(stripe stripe) map[string]int {
+		counts := make(map[string]int)
+		for _, match := range searchWords(stripe.rows, words, directions) {
+			// the top row of the match, which is where it ends when going up
+			top := min(match.Row, match.Row+(len([]rune(match.Word))-1)*match.Direction.DRow)
+			if top < stripe.owned {
+				counts[match.Word]++
+			}
+		}
+		return counts
+	})
+}
+
+// matchTemplateStriped counts the matches of the template, like matchTemplate.
+func matchTemplateStriped(r io.Reader) (int, error) {
+	overlap := 0
+	for _, variant := range template.variants() {
+		overlap = max(overlap, len(variant)-1)
+	}
+	counts, err := searchStriped(r, overlap, func(stripe stripe) map[string]int {
+		count := 0
+		for _, match := range matchTemplate(stripe.rows, template) {
+			if match.Row < stripe.owned {
+				count++
+			}
+		}
+		return map[string]int{"": count}
+	})
+	return counts[""], err
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A template is a small grid of letters that has to appear as a block in the
+// letter grid, like the X-MAS shape of part2:
+//
+//	M.S
+//	.A.
+//	M.S
+//
+// Cells with the wildcard '.' can hold any letter. A template also matches
+// rotated and reflected, so it is expanded into its distinct variants up front.
+
+const templateWildcard = '.'
+
+type Template [][]rune
+
+// parseTemplate reads a template written on one line with the rows separated
+// by slashes, such as "M.S/.A./M.S".
+func parseTemplate(pattern string) (Template, error) {
+	template := make(Template, 0)
+	for _, row := range strings.Split(pattern, "/") {
+		row = strings.TrimSpace(row)
+		if len(template) > 0 && len([]rune(row)) != len(template[0]) {
+			return nil, fmt.Errorf("template rows must all be %d long, found %q", len(template[0]), row)
+		}
+		template = append(template, []rune(row))
+	}
+	if len(template[0]) == 0 {
+		return nil, fmt.Errorf("template %q is empty", pattern)
+	}
+	return template, nil
+}
+
+func (template Template) String() string {
+	rows := make([]string, len(template))
+	for i, row := range template {
+		rows[i] = string(row)
+	}
+	return strings.Join(rows, "/")
+}
+
+// rotate turns the template a quarter turn clockwise.
+func (template Template) rotate() Template {
+	rotated := make(Template, len(template[0]))
+	for col := range rotated {
+		rotated[col] = make([]rune, len(template))
+		for row := range template {
+			rotated[col][len(template)-1-row] = template[row][col]
+		}
+	}
+	return rotated
+}
+
+// reflect mirrors the template left to right.
+func (template Template) reflect() Template {
+	reflected := make(Template, len(template))
+	for row := range template {
+		reflected[row] = make([]rune, len(template[row]))
+		for col := range template[row] {
+			reflected[row][len(template[row])-1-col] = template[row][col]
+		}
+	}
+	return reflected
+}
+
+// variants returns the distinct rotations and reflections of the template. A
+// symmetric shape has fewer than eight, so it isn't counted twice in the same
+// place.
+func (template Template) variants() []Template {
+	variants := make([]Template, 0, 8)
+	seen := make(map[string]bool)
+	for _, start := range []Template{template, template.reflect()} {
+		variant := start
+		for turn := 0; turn < 4; turn++ {
+			if key := variant.String(); !seen[key] {
+				seen[key] = true
+				variants = append(variants, variant)
+			}
+			variant = variant.rotate()
+		}
+	}
+	return variants
+}
+
+func (template Template) matchesAt(grid [][]rune, row, col int) bool {
+	for r, templateRow := range template {
+		for c, letter := range templateRow {
+			if !inGrid(grid, row+r, col+c) {
+				return false
+			}
+			if letter != templateWildcard && grid[row+r][col+c] != letter {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// TemplateMatch is a variant of the template found with its top left corner
+// at (Row, Col), counting from 0.
+type TemplateMatch struct {
+	Row, Col int
+	Variant  Template
+}
+
+func (match TemplateMatch) String() string {
+	return fmt.Sprintf("%s at (%d, %d)", match.Variant, match.Row, match.Col)
+}
+
+// matchTemplate locates every variant of the template in the grid.
+func matchTemplate(grid [][]rune, template Template) []TemplateMatch {
+	matches := make([]TemplateMatch, 0)
+	for _, variant := range template.variants() {
+		for row := range grid {
+			for col := range grid[row] {
+				if variant.matchesAt(grid, row, col) {
+					matches = append(matches, TemplateMatch{Row: row, Col: col, Variant: variant})
+				}
+			}
+		}
+	}
+	return matches
+}
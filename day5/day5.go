@@ -1,50 +1,41 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
-	"os"
-	"regexp"
-	"slices"
-	"sort"
-	"strconv"
-	"strings"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
+// Set by --repairs to print the fewest moves that fix each update in part2.
+var showRepairs bool
+
 func main() {
+	flag.BoolVar(&showRepairs, "repairs", false, "print the fewest moves that fix each unordered update")
+	export := flag.String("export", "", "write the rule graph instead of solving: dot or json")
+	update := flag.Int("update", 0, "highlight the pages of this update in the export, counting from 1")
+	subgraph := flag.Bool("subgraph", false, "only export the rules between the pages of --update")
+	reduce := flag.Bool("reduce", false, "leave out the rules that follow from the others in the export")
+	lint := flag.Bool("lint", false, "check the rules and updates for problems instead of solving")
+	flag.Parse()
+	if *lint {
+		lintManual()
+		return
+	}
+	if *export != "" {
+		exportGraph(*export, *update, *subgraph, *reduce)
+		return
+	}
 	part1()
 	part2()
 }
 
-func arePagesInOrder(pages []int, forwardMap map[int][]int, backwardMap map[int][]int) bool {
-	// Loop through the pages and check the forwardMap and backwardMap
+func arePagesInOrder(pages []int, rules *RuleSet) bool {
+	// No page may have a rule saying it belongs before a page ahead of it
 	for i, page := range pages {
-		var pagesBehind []int
-		var pagesAhead []int
-		if i > 0 {
-			pagesBehind = pages[:i]
-		} else {
-			pagesBehind = []int{}
-		}
-		if i+1 < len(pages) {
-			pagesAhead = pages[i+1:]
-		} else {
-			pagesAhead = []int{}
-		}
-		for _, behind := range pagesBehind {
-			// Look through the forwardMap. If it finds an entry there, then it is not in the right order
-			if forwardMap[page] != nil {
-				if slices.Contains(forwardMap[page], behind) {
-					return false
-				}
-			}
-		}
-		for _, ahead := range pagesAhead {
-			// Look through the backwardMap. If it finds an entry there, then it is not in the right order
-			if backwardMap[page] != nil {
-				if slices.Contains(backwardMap[page], ahead) {
-					return false
-				}
+		for _, behind := range pages[:i] {
+			if rules.Before(page, behind) {
+				return false
 			}
 		}
 	}
@@ -54,108 +45,69 @@ func arePagesInOrder(pages []int, forwardMap map[int][]int, backwardMap map[int]
 func part1() {
 	// https://adventofcode.com/2024/day/5
 	// Confirm that pages are in the right order
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
 	}
 	defer file.Close()
-	forwardMap := make(map[int][]int)
-	backwardMap := make(map[int][]int)
-	orderPairPattern := regexp.MustCompile(`(\d+)\|(\d+)`)
+	manual, err := readManual(file)
+	if err != nil {
+		fmt.Println("Error reading file: ", err)
+	}
 	result := 0
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		pages := make([]int, 0)
-		if strings.Contains(line, "|") {
-			parts := orderPairPattern.FindStringSubmatch(line)
-			if len(parts) == 3 {
-				left, _ := strconv.Atoi(parts[1])
-				right, _ := strconv.Atoi(parts[2])
-				forwardMap[left] = append(forwardMap[left], right)
-				backwardMap[right] = append(backwardMap[right], left)
-			}
-		} else if strings.Contains(line, ",") {
-			// Split the line by commas
-			parts := strings.Split(line, ",")
-			for _, part := range parts {
-				pageNumber, _ := strconv.Atoi(part)
-				pages = append(pages, pageNumber)
-			}
-			// Loop through the pages and check the forwardMap and backwardMap
-			if arePagesInOrder(pages, forwardMap, backwardMap) {
-				// Now we know this sequence of pages is in the right order.
-				fmt.Println("Safety manual update (", line, ") is in the right order.")
-				// Get the middle element
-				result += pages[len(pages)/2]
-			}
+	for _, update := range manual.Updates {
+		pages := update.Pages
+		if arePagesInOrder(pages, manual.Rules) {
+			// Now we know this sequence of pages is in the right order.
+			fmt.Println("Safety manual update (", update.Text, ") is in the right order.")
+			// Get the middle element
+			result += pages[len(pages)/2]
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		fmt.Println("Error reading file: ", err)
-	}
 	fmt.Println("The final result is: ", result)
 }
 
 func part2() {
 	// https://adventofcode.com/2024/day/5#part2
 	// Confirm that pages are in the right order
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
 	}
 	defer file.Close()
-	forwardMap := make(map[int][]int)
-	backwardMap := make(map[int][]int)
-	orderPairPattern := regexp.MustCompile(`(\d+)\|(\d+)`)
+	manual, err := readManual(file)
+	if err != nil {
+		fmt.Println("Error reading file: ", err)
+	}
 	result := 0
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		pages := make([]int, 0)
-		if strings.Contains(line, "|") {
-			parts := orderPairPattern.FindStringSubmatch(line)
-			if len(parts) == 3 {
-				left, _ := strconv.Atoi(parts[1])
-				right, _ := strconv.Atoi(parts[2])
-				forwardMap[left] = append(forwardMap[left], right)
-				backwardMap[right] = append(backwardMap[right], left)
-			}
-		} else if strings.Contains(line, ",") {
-			// Split the line by commas
-			parts := strings.Split(line, ",")
-			for _, part := range parts {
-				pageNumber, _ := strconv.Atoi(part)
-				pages = append(pages, pageNumber)
-			}
-			// Loop through the pages and check the forwardMap and backwardMap
-			if !arePagesInOrder(pages, forwardMap, backwardMap) {
-				// Now we know this sequence of pages is not in the right order.
-				fmt.Println("Safety manual update (", line, ") is not in the right order.")
-				// We have to sort the pages now based on a custom comparator function
-				sort.Slice(pages, func(i, j int) bool {
-					if forwardMap[pages[i]] != nil {
-						if slices.Contains(forwardMap[pages[i]], pages[j]) {
-							return true
-						}
-					}
-					if backwardMap[pages[j]] != nil {
-						if slices.Contains(backwardMap[pages[j]], pages[i]) {
-							return true
-						}
-					}
-					return false
-				})
-				fmt.Println("The sorted pages are: ", pages)
-				// Get the middle element
-				result += pages[len(pages)/2]
+	for _, update := range manual.Updates {
+		pages := update.Pages
+		if arePagesInOrder(pages, manual.Rules) {
+			continue
+		}
+		// Now we know this sequence of pages is not in the right order.
+		fmt.Println("Safety manual update (", update.Text, ") is not in the right order.")
+		// Put the pages in order with a topological sort of the rules between them
+		reordering := reorderPages(pages, manual.Rules)
+		if reordering.Cycle != nil {
+			fmt.Println("Safety manual update (", update.Text, ") can't be ordered:", reordering)
+			continue
+		}
+		if reordering.Ambiguous != nil {
+			fmt.Println("Safety manual update (", update.Text, ") has more than one order:", reordering)
+		}
+		// The smallest change that fixes the update, for the printers
+		if showRepairs {
+			if repair, ok := repairPages(pages, manual.Rules); ok {
+				fmt.Println("Safety manual update (", update.Text, ") needs", repair)
 			}
 		}
-	}
-	if err := scanner.Err(); err != nil {
-		fmt.Println("Error reading file: ", err)
+		pages = reordering.Pages
+		fmt.Println("The sorted pages are: ", pages)
+		// Get the middle element
+		result += pages[len(pages)/2]
 	}
 	fmt.Println("The final result is: ", result)
 }
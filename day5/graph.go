@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
+)
+
+// The rule graph has a node for every page and an edge a -> b for every rule
+// a|b. It can be exported for Graphviz or as JSON adjacency lists, either
+// whole or only the pages of one update.
+
+type RuleGraph struct {
+	Pages       []int         // sorted
+	Successors  map[int][]int // sorted, without duplicate rules
+	Highlighted map[int]bool
+}
+
+// newRuleGraph builds the graph over the given pages, or over every page in
+// the rules if pages is nil.
+func newRuleGraph(rules *RuleSet, pages []int) *RuleGraph {
+	graph := &RuleGraph{Successors: make(map[int][]int), Highlighted: make(map[int]bool)}
+	if pages == nil {
+		pages = rules.Pages()
+	}
+	include := make(map[int]bool)
+	for _, page := range pages {
+		include[page] = true
+	}
+	for page := range include {
+		graph.Pages = append(graph.Pages, page)
+		successors := make([]int, 0)
+		for _, after := range rules.Successors(page) {
+			if include[after] && !slices.Contains(successors, after) {
+				successors = append(successors, after)
+			}
+		}
+		sort.Ints(successors)
+		graph.Successors[page] = successors
+	}
+	sort.Ints(graph.Pages)
+	return graph
+}
+
+// reachableWithout reports whether to can be reached from from without using
+// the direct edge between them.
+func (graph *RuleGraph) reachableWithout(from, to int) bool {
+	seen := map[int]bool{from: true}
+	stack := make([]int, 0)
+	for _, successor := range graph.Successors[from] {
+		if successor != to {
+			stack = append(stack, successor)
+			seen[successor] = true
+		}
+	}
+	for len(stack) > 0 {
+		page := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if page == to {
+			return true
+		}
+		for _, successor := range graph.Successors[page] {
+			if !seen[successor] {
+				seen[successor] = true
+				stack = append(stack, successor)
+			}
+		}
+	}
+	return false
+}
+
+// reduce removes every rule that follows from the others, leaving the
+// transitive reduction. It is only unique for an acyclic graph, so a graph
+// with a cycle is left alone and the cycle is returned.
+func (graph *RuleGraph) reduce() []int {
+	rules := NewRuleSet()
+	for page, successors := range graph.Successors {
+		for _, successor := range successors {
+			rules.Add(page, successor, 0)
+		}
+	}
+	if cycle := reorderPages(graph.Pages, rules).Cycle; cycle != nil {
+		return cycle
+	}
+	reduced := make(map[int][]int, len(graph.Successors))
+	for _, page := range graph.Pages {
+		reduced[page] = make([]int, 0)
+		for _, successor := range graph.Successors[page] {
+			if !graph.reachableWithout(page, successor) {
+				reduced[page] = append(reduced[page], successor)
+			}
+		}
+	}
+	graph.Successors = reduced
+	return nil
+}
+
+func (graph *RuleGraph) writeDOT(w io.Writer) error {
+	var builder strings.Builder
+	builder.WriteString("digraph rules {\n")
+	for _, page := range graph.Pages {
+		if graph.Highlighted[page] {
+			fmt.Fprintf(&builder, "  %d [style=filled, fillcolor=gold];\n", page)
+		} else {
+			fmt.Fprintf(&builder, "  %d;\n", page)
+		}
+	}
+	for _, page := range graph.Pages {
+		for _, successor := range graph.Successors[page] {
+			if graph.Highlighted[page] && graph.Highlighted[successor] {
+				fmt.Fprintf(&builder, "  %d -> %d [penwidth=2];\n", page, successor)
+			} else {
+				fmt.Fprintf(&builder, "  %d -> %d;\n", page, successor)
+			}
+		}
+	}
+	builder.WriteString("}\n")
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+type graphNode struct {
+	Page        int   `json:"page"`
+	Before      []int `json:"before"` // the pages this one has to come before
+	Highlighted bool  `json:"highlighted,omitempty"`
+}
+
+func (graph *RuleGraph) writeJSON(w io.Writer) error {
+	nodes := make([]graphNode, 0, len(graph.Pages))
+	for _, page := range graph.Pages {
+		nodes = append(nodes, graphNode{Page: page, Before: graph.Successors[page], Highlighted: graph.Highlighted[page]})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(nodes)
+}
+
+// exportGraph writes the rule graph. update picks an update counting from 1,
+// whose pages are highlighted, or make up the whole graph with subgraph.
+func exportGraph(format string, update int, subgraph, reduce bool) {
+	if format != "dot" && format != "json" {
+		fmt.Println("Unknown export format:", format)
+		return
+	}
+	file, err := input.Open("input.txt")
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return
+	}
+	defer file.Close()
+	manual, err := readManual(file)
+	if err != nil {
+		fmt.Println("Error reading file: ", err)
+		return
+	}
+	updates := manual.Updates
+	if update < 0 || update > len(updates) || (subgraph && update == 0) {
+		fmt.Println("Pick an update from 1 to", len(updates), "with --update")
+		return
+	}
+	var pages []int
+	if subgraph {
+		pages = updates[update-1].Pages
+	}
+	graph := newRuleGraph(manual.Rules, pages)
+	if update > 0 {
+		for _, page := range updates[update-1].Pages {
+			graph.Highlighted[page] = true
+		}
+	}
+	if reduce {
+		if cycle := graph.reduce(); cycle != nil {
+			fmt.Println("Can't reduce the rules, they form a cycle:", cycle)
+			return
+		}
+	}
+	if format == "dot" {
+		err = graph.writeDOT(os.Stdout)
+	} else {
+		err = graph.writeJSON(os.Stdout)
+	}
+	if err != nil {
+		fmt.Println("Error writing graph:", err)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
+)
+
+// The linter reports what the solver silently works around: repeated and
+// malformed lines, rules that contradict each other, cycles, pages no rule
+// mentions and updates without a middle page.
+//
+// A cycle in the whole rule set isn't necessarily a mistake, the puzzle input
+// has them, since only the rules between the pages of one update matter. So
+// cycles are reported for the rule set as a whole, as a note, and for every
+// update they actually break.
+
+// stronglyConnected returns the groups of pages that all reach each other
+// through the rules, with Tarjan's algorithm, leaving out single pages.
+func stronglyConnected(rules *RuleSet) [][]int {
+	index := make(map[int]int)
+	lowLink := make(map[int]int)
+	onStack := make(map[int]bool)
+	stack := make([]int, 0)
+	components := make([][]int, 0)
+	var visit func(page int)
+	visit = func(page int) {
+		index[page] = len(index)
+		lowLink[page] = index[page]
+		stack = append(stack, page)
+		onStack[page] = true
+		for _, successor := range rules.Successors(page) {
+			if _, seen := index[successor]; !seen {
+				visit(successor)
+				lowLink[page] = min(lowLink[page], lowLink[successor])
+			} else if onStack[successor] {
+				lowLink[page] = min(lowLink[page], index[successor])
+			}
+		}
+		if lowLink[page] != index[page] {
+			return
+		}
+		component := make([]int, 0)
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == page {
+				break
+			}
+		}
+		if len(component) > 1 {
+			sort.Ints(component)
+			components = append(components, component)
+		}
+	}
+	for _, page := range rules.Pages() {
+		if _, seen := index[page]; !seen {
+			visit(page)
+		}
+	}
+	return components
+}
+
+func printProblems(title string, problems []ManualProblem) {
+	if len(problems) == 0 {
+		return
+	}
+	fmt.Printf("%s (%d):\n", title, len(problems))
+	for _, problem := range problems {
+		fmt.Printf("  line %d: %s: %s\n", problem.Line, problem.Text, problem.Reason)
+	}
+}
+
+func lintManual() {
+	file, err := input.Open("input.txt")
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return
+	}
+	defer file.Close()
+	manual, err := readManual(file)
+	if err != nil {
+		fmt.Println("Error reading file: ", err)
+		return
+	}
+	rules := manual.Rules
+
+	contradictions := make([]ManualProblem, 0)
+	for _, before := range rules.Pages() {
+		for _, after := range rules.Successors(before) {
+			// report each pair once, on the later of its two lines
+			if rules.Before(after, before) && rules.Line(before, after) > rules.Line(after, before) {
+				contradictions = append(contradictions, ManualProblem{rules.Line(before, after), fmt.Sprintf("%d|%d", before, after),
+					fmt.Sprintf("contradicts %d|%d on line %d", after, before, rules.Line(after, before))})
+			}
+		}
+	}
+	sort.Slice(contradictions, func(i, j int) bool { return contradictions[i].Line < contradictions[j].Line })
+
+	ruled := make(map[int]bool)
+	for _, page := range rules.Pages() {
+		ruled[page] = true
+	}
+	orphans := make([]ManualProblem, 0)
+	evenLength := make([]ManualProblem, 0)
+	updateCycles := make([]ManualProblem, 0)
+	for _, update := range manual.Updates {
+		unruled := make([]int, 0)
+		for _, page := range update.Pages {
+			if !ruled[page] {
+				unruled = append(unruled, page)
+			}
+		}
+		if len(unruled) > 0 {
+			orphans = append(orphans, ManualProblem{update.Line, update.Text, fmt.Sprintf("no rule mentions %v", unruled)})
+		}
+		if len(update.Pages)%2 == 0 {
+			evenLength = append(evenLength, ManualProblem{update.Line, update.Text, fmt.Sprintf("%d pages, so there is no middle page", len(update.Pages))})
+		}
+		if cycle := reorderPages(update.Pages, rules).Cycle; cycle != nil {
+			updateCycles = append(updateCycles, ManualProblem{update.Line, update.Text, fmt.Sprintf("the rules form a cycle %v", cycle)})
+		}
+	}
+
+	printProblems("Malformed lines", manual.Malformed)
+	printProblems("Duplicate rules", manual.Duplicates)
+	printProblems("Contradictory rules", contradictions)
+	printProblems("Updates the rules can't order", updateCycles)
+	printProblems("Updates with pages no rule mentions", orphans)
+	printProblems("Updates without a middle page", evenLength)
+	components := stronglyConnected(rules)
+	for _, component := range components {
+		cycle := reorderPages(component, rules).Cycle
+		fmt.Printf("Note: the rules for %d pages form cycles, for example %v\n", len(component), cycle)
+	}
+	issues := len(manual.Malformed) + len(manual.Duplicates) + len(contradictions) + len(updateCycles) + len(orphans) + len(evenLength)
+	fmt.Println("The # of lint issues is: ", issues)
+}
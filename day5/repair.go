@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// A misordered update can be fixed by taking some pages out and putting them
+// back elsewhere. The pages that stay put have to already be in an order the
+// rules allow, so the fewest moves leave the longest such subsequence alone
+// and move everything else.
+//
+// Two pages can stay put in the order they are in unless the rules, followed
+// through other pages of the update, say the opposite. Checking each pair is
+// enough: a set of pages that passes can always be completed to a full order.
+// The longest such subsequence comes from a matching, see longestKept.
+
+type Move struct {
+	Page  int
+	After int // the page it goes right after, ignored with ToFront
+	// ToFront moves the page to the start of the update.
+	ToFront bool
+}
+
+func (move Move) String() string {
+	if move.ToFront {
+		return fmt.Sprintf("move %d to the front", move.Page)
+	}
+	return fmt.Sprintf("move %d after %d", move.Page, move.After)
+}
+
+type Repair struct {
+	Kept   []int // the longest subsequence that stays put
+	Moves  []Move
+	Result []int // the update after the moves
+}
+
+// repairPages finds the fewest moves that put the update in order. It returns
+// false if the rules between the pages have a cycle.
+func repairPages(pages []int, rules *RuleSet) (Repair, bool) {
+	n := len(pages)
+	// must[i][j]: the rules put page i before page j, directly or through others
+	must := make([][]bool, n)
+	for i := range must {
+		must[i] = make([]bool, n)
+		for j := range must[i] {
+			must[i][j] = i != j && rules.Before(pages[i], pages[j])
+		}
+	}
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			if !must[i][k] {
+				continue
+			}
+			for j := 0; j < n; j++ {
+				if must[k][j] {
+					must[i][j] = true
+				}
+			}
+		}
+	}
+	for i := 0; i < n; i++ {
+		if must[i][i] {
+			return Repair{}, false
+		}
+	}
+
+	kept := longestKept(must)
+	var repair Repair
+	for i := 0; i < n; i++ {
+		if kept[i] {
+			repair.Kept = append(repair.Kept, pages[i])
+		}
+	}
+
+	// the target order: the rules, plus the kept pages staying in order
+	target := NewRuleSet()
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if must[i][j] {
+				target.Add(i, j, 0)
+			}
+		}
+	}
+	last := -1
+	for i := 0; i < n; i++ {
+		if kept[i] {
+			if last >= 0 {
+				target.Add(last, i, 0)
+			}
+			last = i
+		}
+	}
+	positions := make([]int, n)
+	for i := range positions {
+		positions[i] = i
+	}
+	order := reorderPages(positions, target).Pages
+
+	// move the other pages, in the target order, right after the page before them
+	current := slices.Clone(positions)
+	for k, position := range order {
+		if kept[position] {
+			continue
+		}
+		current = slices.Delete(current, slices.Index(current, position), slices.Index(current, position)+1)
+		move := Move{Page: pages[position], ToFront: k == 0}
+		insertAt := 0
+		if k > 0 {
+			move.After = pages[order[k-1]]
+			insertAt = slices.Index(current, order[k-1]) + 1
+		}
+		current = slices.Insert(current, insertAt, position)
+		repair.Moves = append(repair.Moves, move)
+	}
+	for _, position := range current {
+		repair.Result = append(repair.Result, pages[position])
+	}
+	return repair, true
+}
+
+// longestKept picks the most pages that can stay put. Two pages clash when
+// the earlier one has to come after the later one. Clashing is transitive,
+// since it follows the update one way and the rules the other, so the most
+// pages without a clash is the largest antichain of that order. By Dilworth
+// and König, that is every page whose left copy is reachable from an
+// unmatched left page by alternating paths in a maximum matching of the
+// clashes, and whose right copy isn't.
+func longestKept(must [][]bool) []bool {
+	n := len(must)
+	clash := func(j, i int) bool { return j < i && must[i][j] }
+	matchedLeft := make([]int, n) // the right page matched to each left page
+	matchedRight := make([]int, n)
+	for i := 0; i < n; i++ {
+		matchedLeft[i], matchedRight[i] = -1, -1
+	}
+	var augment func(u int, seen []bool) bool
+	augment = func(u int, seen []bool) bool {
+		for v := 0; v < n; v++ {
+			if !clash(u, v) || seen[v] {
+				continue
+			}
+			seen[v] = true
+			if matchedRight[v] < 0 || augment(matchedRight[v], seen) {
+				matchedLeft[u], matchedRight[v] = v, u
+				return true
+			}
+		}
+		return false
+	}
+	for u := 0; u < n; u++ {
+		augment(u, make([]bool, n))
+	}
+
+	reachedLeft := make([]bool, n)
+	reachedRight := make([]bool, n)
+	queue := make([]int, 0)
+	for u := 0; u < n; u++ {
+		if matchedLeft[u] < 0 {
+			reachedLeft[u] = true
+			queue = append(queue, u)
+		}
+	}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for v := 0; v < n; v++ {
+			if clash(u, v) && !reachedRight[v] && matchedLeft[u] != v {
+				reachedRight[v] = true
+				if w := matchedRight[v]; w >= 0 && !reachedLeft[w] {
+					reachedLeft[w] = true
+					queue = append(queue, w)
+				}
+			}
+		}
+	}
+	kept := make([]bool, n)
+	for i := 0; i < n; i++ {
+		kept[i] = reachedLeft[i] && !reachedRight[i]
+	}
+	return kept
+}
+
+func (repair Repair) String() string {
+	moves := make([]string, len(repair.Moves))
+	for i, move := range repair.Moves {
+		moves[i] = move.String()
+	}
+	noun := "moves"
+	if len(repair.Moves) == 1 {
+		noun = "move"
+	}
+	return fmt.Sprintf("%d %s: %s", len(repair.Moves), noun, strings.Join(moves, ", "))
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RuleSet indexes the page ordering rules by their ordered pair, so checking
+// whether a rule puts one page before another is a single lookup.
+
+type rulePair struct {
+	before, after int
+}
+
+type RuleSet struct {
+	pairs      map[rulePair]int // the pair -> the line of the rule, 0 if added in code
+	successors map[int][]int    // the pages each page has to come before, in the order added
+}
+
+func NewRuleSet() *RuleSet {
+	return &RuleSet{pairs: make(map[rulePair]int), successors: make(map[int][]int)}
+}
+
+// Add records the rule before|after from the given line, returning false if
+// the rule was already there.
+func (rules *RuleSet) Add(before, after, line int) bool {
+	pair := rulePair{before, after}
+	if _, ok := rules.pairs[pair]; ok {
+		return false
+	}
+	rules.pairs[pair] = line
+	rules.successors[before] = append(rules.successors[before], after)
+	return true
+}
+
+// Before reports whether a rule says page before comes before page after.
+func (rules *RuleSet) Before(before, after int) bool {
+	_, ok := rules.pairs[rulePair{before, after}]
+	return ok
+}
+
+func (rules *RuleSet) Successors(page int) []int {
+	return rules.successors[page]
+}
+
+// Line returns the line the rule before|after was read from.
+func (rules *RuleSet) Line(before, after int) int {
+	return rules.pairs[rulePair{before, after}]
+}
+
+// Pages returns every page that is in a rule, sorted.
+func (rules *RuleSet) Pages() []int {
+	seen := make(map[int]bool)
+	for pair := range rules.pairs {
+		seen[pair.before] = true
+		seen[pair.after] = true
+	}
+	pages := make([]int, 0, len(seen))
+	for page := range seen {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+	return pages
+}
+
+type Update struct {
+	Line  int
+	Text  string
+	Pages []int
+}
+
+// ManualProblem is a line of the manual that was left out, with the reason.
+type ManualProblem struct {
+	Line   int
+	Text   string
+	Reason string
+}
+
+type Manual struct {
+	Rules      *RuleSet
+	Updates    []Update
+	Duplicates []ManualProblem // rules that repeat an earlier one
+	Malformed  []ManualProblem // lines that are neither a rule nor an update
+}
+
+var rulePattern = regexp.MustCompile(`^(\d+)\|(\d+)$`)
+
+// readManual reads the rules and the updates, keeping track of the lines it
+// had to skip.
+func readManual(r io.Reader) (*Manual, error) {
+	manual := &Manual{Rules: NewRuleSet(), Updates: make([]Update, 0)}
+	lineNumber := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.Contains(line, "|") {
+			parts := rulePattern.FindStringSubmatch(line)
+			if parts == nil {
+				manual.Malformed = append(manual.Malformed, ManualProblem{lineNumber, line, "a rule has to be two page numbers like 47|53"})
+				continue
+			}
+			before, err1 := strconv.Atoi(parts[1])
+			after, err2 := strconv.Atoi(parts[2])
+			if err1 != nil || err2 != nil {
+				manual.Malformed = append(manual.Malformed, ManualProblem{lineNumber, line, "page number out of range"})
+				continue
+			}
+			if before == after {
+				manual.Malformed = append(manual.Malformed, ManualProblem{lineNumber, line, "a page can't come before itself"})
+				continue
+			}
+			if !manual.Rules.Add(before, after, lineNumber) {
+				manual.Duplicates = append(manual.Duplicates, ManualProblem{lineNumber, line,
+					"repeats the rule on line " + strconv.Itoa(manual.Rules.Line(before, after))})
+			}
+			continue
+		}
+		pages := make([]int, 0)
+		for _, part := range strings.Split(line, ",") {
+			pageNumber, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				pages = nil
+				break
+			}
+			pages = append(pages, pageNumber)
+		}
+		if pages == nil {
+			manual.Malformed = append(manual.Malformed, ManualProblem{lineNumber, line, "an update has to be page numbers separated by commas"})
+			continue
+		}
+		manual.Updates = append(manual.Updates, Update{Line: lineNumber, Text: line, Pages: pages})
+	}
+	return manual, scanner.Err()
+}
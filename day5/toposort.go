@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Reordering is the result of putting an update in order with Kahn's
+// algorithm, using only the rules between pages of the update.
+type Reordering struct {
+	Pages []int // the update in order, only complete without a cycle
+	// Pages whose rules form a cycle, first page repeated at the end, so no
+	// order can satisfy them. nil when there is none.
+	Cycle []int
+	// Two pages no rule puts in order, found when more than one page could
+	// come next. nil when the rules allow only one order.
+	Ambiguous []int
+}
+
+func reorderPages(pages []int, rules *RuleSet) Reordering {
+	// the subgraph induced by the update, over positions so repeated pages work
+	successors := make([][]int, len(pages))
+	inDegree := make([]int, len(pages))
+	for i, before := range pages {
+		for j, after := range pages {
+			if i != j && rules.Before(before, after) {
+				successors[i] = append(successors[i], j)
+				inDegree[j]++
+			}
+		}
+	}
+	var reordering Reordering
+	ready := make([]int, 0)
+	for i := range pages {
+		if inDegree[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	placed := make([]bool, len(pages))
+	for len(ready) > 0 {
+		if len(ready) > 1 && reordering.Ambiguous == nil {
+			reordering.Ambiguous = []int{pages[ready[0]], pages[ready[1]]}
+		}
+		// take the ready pages in the order of the update, which keeps
+		// pages with no rules between them where they were
+		next := ready[0]
+		ready = ready[1:]
+		placed[next] = true
+		reordering.Pages = append(reordering.Pages, pages[next])
+		for _, successor := range successors[next] {
+			inDegree[successor]--
+			if inDegree[successor] == 0 {
+				ready = append(ready, successor)
+				slices.Sort(ready)
+			}
+		}
+	}
+	if len(reordering.Pages) < len(pages) {
+		reordering.Cycle = findCycle(pages, successors, placed)
+	}
+	return reordering
+}
+
+// findCycle follows rules backwards from a page Kahn's algorithm couldn't
+// place. Every such page has a rule from another unplaced page, so the walk
+// has to come back to a page it has seen.
+func findCycle(pages []int, successors [][]int, placed []bool) []int {
+	predecessor := make([]int, len(pages))
+	for i := range pages {
+		predecessor[i] = -1
+	}
+	start := -1
+	for i, successorList := range successors {
+		if placed[i] {
+			continue
+		}
+		start = i
+		for _, successor := range successorList {
+			if !placed[successor] {
+				predecessor[successor] = i
+			}
+		}
+	}
+	seen := make(map[int]int) // position -> step of the walk
+	walk := make([]int, 0)
+	for current := start; ; current = predecessor[current] {
+		if step, ok := seen[current]; ok {
+			cycle := make([]int, 0, len(walk)-step+1)
+			// the walk went backwards, so read it in reverse
+			for k := len(walk) - 1; k >= step; k-- {
+				cycle = append(cycle, pages[walk[k]])
+			}
+			return append(cycle, cycle[0])
+		}
+		seen[current] = len(walk)
+		walk = append(walk, current)
+	}
+}
+
+func (reordering Reordering) String() string {
+	switch {
+	case reordering.Cycle != nil:
+		return fmt.Sprintf("the rules form a cycle %v, no order satisfies them", reordering.Cycle)
+	case reordering.Ambiguous != nil:
+		return fmt.Sprintf("%v, but no rule orders %d and %d, so other orders work too", reordering.Pages, reordering.Ambiguous[0], reordering.Ambiguous[1])
+	}
+	return fmt.Sprint(reordering.Pages)
+}
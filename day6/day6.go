@@ -2,13 +2,20 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
-	"os"
 	"sync"
 	"time"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
+// Set by --render to print the grid with the guard's path after part1.
+var render bool
+
 func main() {
+	flag.BoolVar(&render, "render", false, "print the grid with the guard's path")
+	flag.Parse()
 	part1()
 	part2()
 }
@@ -23,6 +30,12 @@ func deepCopyGrid(grid [][]rune) [][]rune {
 	return newGrid
 }
 
+func printGrid(grid [][]rune) {
+	for _, row := range grid {
+		fmt.Println(string(row))
+	}
+}
+
 func walkGrid(grid [][]rune, posRow, posCol int, direction rune) int {
 	visited := make(map[string]bool)
 	visited[fmt.Sprintf("%d,%d,%c", posRow, posCol, direction)] = true
@@ -83,7 +96,7 @@ func walkGrid(grid [][]rune, posRow, posCol int, direction rune) int {
 func part1() {
 	// https://adventofcode.com/2024/day/6
 	// Find path through the grid while navigating barriers
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
@@ -117,6 +130,9 @@ func part1() {
 	// We should have the grid and the starting position now. Let's navigate the grid
 	gridCopy := deepCopyGrid(grid)
 	result = walkGrid(gridCopy, posRow, posCol, direction)
+	if render {
+		printGrid(gridCopy)
+	}
 	fmt.Println("The final result is: ", result)
 }
 
@@ -130,7 +146,7 @@ func timer(name string) func() {
 func part2() {
 	// https://adventofcode.com/2024/day/6#part2
 	// Loop through and find the number of places we can place a barrier to get an infinite loop
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
@@ -2,12 +2,14 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
-	"os"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/answer"
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
 func timer(name string) func() {
@@ -17,15 +19,21 @@ func timer(name string) func() {
 	}
 }
 
+// Set by --bigint to keep the test values and the sum in an answer.Int backed
+// by math/big.
+var useBigInt bool
+
 func main() {
+	flag.BoolVar(&useBigInt, "bigint", false, "use arbitrary-precision integers for the answers")
+	flag.Parse()
 	part1()
 	part2()
 }
 
 type Operation struct {
-	operand      int
+	operand      answer.Int
 	operator     string
-	currentValue int
+	currentValue answer.Int
 }
 
 type Stack struct {
@@ -49,7 +57,7 @@ func (s *Stack) Pop() Operation {
 	return popped
 }
 
-func printSolution(stack Stack, firstValue int) {
+func printSolution(stack Stack, firstValue answer.Int) {
 	// Print the solution
 	fmt.Print(firstValue, " ")
 	for i := len(stack.items) - 1; i >= 0; i-- {
@@ -58,7 +66,7 @@ func printSolution(stack Stack, firstValue int) {
 	fmt.Println("=", stack.items[0].currentValue)
 }
 
-func calculate(testValue int, operands []int) bool {
+func calculate(testValue answer.Int, operands []answer.Int) bool {
 	// Traverse the operands backwards and see if we can get to the testValue
 	// If we can, return true. Otherwise, return false
 	stack := Stack{items: make([]Operation, 0)}
@@ -70,18 +78,18 @@ func calculate(testValue int, operands []int) bool {
 		// two possible operators: + and *, so we need to use the opposite operator - and /
 		// we start with the division and see if we can get a valid whole number
 		newValue := currentValue
-		if currentValue%operand == 0 && nextOperator == "*" {
-			newValue = currentValue / operand
+		if currentValue.Rem(operand).Sign() == 0 && nextOperator == "*" {
+			newValue = currentValue.Quo(operand)
 			stack.Push(Operation{operand: operand, operator: "*", currentValue: currentValue})
 		} else {
-			newValue = currentValue - operand
+			newValue = currentValue.Sub(operand)
 			stack.Push(Operation{operand: operand, operator: "+", currentValue: currentValue})
 			nextOperator = "*" // reset so it'll go down the first path next time
 		}
 		index--
 		if index == 0 {
 			// We are at the beginning.
-			if newValue == operands[0] {
+			if newValue.Cmp(operands[0]) == 0 {
 				printSolution(stack, newValue)
 				return true
 			}
@@ -108,40 +116,51 @@ func calculate(testValue int, operands []int) bool {
 func part1() {
 	// https://adventofcode.com/2024/day/7
 	// Walk an operations tree and determine a valid order of operations
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
 	}
 	defer file.Close()
 	defer timer("part1")()
-	result := 0
+	result := answer.New(0, useBigInt)
 	linePattern := regexp.MustCompile(`(\d+):(( \d+)+)`)
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := scanner.Text()
 		matches := linePattern.FindAllStringSubmatch(line, -1)
 		for _, match := range matches {
-			testValue, _ := strconv.Atoi(match[1])
+			testValue, err := answer.Parse(match[1], useBigInt)
+			if err != nil {
+				fmt.Println("Error converting test value:", err)
+				return
+			}
 			// split and trim the operands
 			operandsString := strings.Split(strings.TrimSpace(match[2]), " ")
-			operands := make([]int, len(operandsString))
+			operands := make([]answer.Int, len(operandsString))
 			for i, operand := range operandsString {
-				operands[i], _ = strconv.Atoi(strings.TrimSpace(operand))
+				operands[i], err = answer.Parse(strings.TrimSpace(operand), useBigInt)
+				if err != nil {
+					fmt.Println("Error converting operand:", err)
+					return
+				}
 			}
 			if calculate(testValue, operands) {
-				result += testValue
+				result = result.Add(testValue)
 			}
 		}
 	}
 	if err := scanner.Err(); err != nil {
 		fmt.Println("Error reading file: ", err)
 	}
-	// We should have the grid and the starting position now. Let's navigate the grid
+	if err := result.Err(); err != nil {
+		fmt.Println("Error computing result:", err)
+		return
+	}
 	fmt.Println("The final result is: ", result)
 }
 
-func popUntilNextPath(stack *Stack, currentIndex int) (newValue int, nextOperator string, newIndex int, finished bool) {
+func popUntilNextPath(stack *Stack, currentIndex int) (newValue answer.Int, nextOperator string, newIndex int, finished bool) {
 	newIndex = currentIndex
 	for {
 		// Pop the stack until we find only "||" operators left
@@ -166,7 +185,7 @@ func popUntilNextPath(stack *Stack, currentIndex int) (newValue int, nextOperato
 	return newValue, nextOperator, newIndex, finished
 }
 
-func calculate2(testValue int, operands []int) bool {
+func calculate2(testValue answer.Int, operands []answer.Int) bool {
 	// Traverse the operands backwards and see if we can get to the testValue
 	// If we can, return true. Otherwise, return false
 	stack := Stack{items: make([]Operation, 0)}
@@ -180,22 +199,22 @@ func calculate2(testValue int, operands []int) bool {
 		newValue := currentValue
 		if nextOperator == "*" {
 			nextOperator = "+"
-			if currentValue%operand == 0 {
-				newValue = currentValue / operand
+			if currentValue.Rem(operand).Sign() == 0 {
+				newValue = currentValue.Quo(operand)
 				stack.Push(Operation{operand: operand, operator: "*", currentValue: currentValue})
 				completedOperation = true
 			}
 		}
 		if !completedOperation && nextOperator == "+" {
-			newValue = currentValue - operand
+			newValue = currentValue.Sub(operand)
 			stack.Push(Operation{operand: operand, operator: "+", currentValue: currentValue})
 			nextOperator = "||"
 			completedOperation = true
 		}
 
 		if !completedOperation && nextOperator == "||" {
-			if strings.HasSuffix(strconv.Itoa(currentValue), strconv.Itoa(operand)) {
-				newValue, _ = strconv.Atoi(strings.TrimSuffix(strconv.Itoa(currentValue), strconv.Itoa(operand)))
+			if strings.HasSuffix(currentValue.String(), operand.String()) {
+				newValue, _ = answer.Parse(strings.TrimSuffix(currentValue.String(), operand.String()), useBigInt)
 				stack.Push(Operation{operand: operand, operator: "||", currentValue: currentValue})
 			} else {
 				var finished bool
@@ -212,7 +231,7 @@ func calculate2(testValue int, operands []int) bool {
 		nextOperator = "*"
 		if index == 0 {
 			// We are at the beginning.
-			if newValue == operands[0] {
+			if newValue.Cmp(operands[0]) == 0 {
 				printSolution(stack, newValue)
 				return true
 			}
@@ -229,35 +248,46 @@ func calculate2(testValue int, operands []int) bool {
 func part2() {
 	// https://adventofcode.com/2024/day/7#part2
 	// Walk an operations tree and determine a valid order of operations
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
 	}
 	defer file.Close()
 	defer timer("part2")()
-	result := 0
+	result := answer.New(0, useBigInt)
 	linePattern := regexp.MustCompile(`(\d+):(( \d+)+)`)
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := scanner.Text()
 		matches := linePattern.FindAllStringSubmatch(line, -1)
 		for _, match := range matches {
-			testValue, _ := strconv.Atoi(match[1])
+			testValue, err := answer.Parse(match[1], useBigInt)
+			if err != nil {
+				fmt.Println("Error converting test value:", err)
+				return
+			}
 			// split and trim the operands
 			operandsString := strings.Split(strings.TrimSpace(match[2]), " ")
-			operands := make([]int, len(operandsString))
+			operands := make([]answer.Int, len(operandsString))
 			for i, operand := range operandsString {
-				operands[i], _ = strconv.Atoi(strings.TrimSpace(operand))
+				operands[i], err = answer.Parse(strings.TrimSpace(operand), useBigInt)
+				if err != nil {
+					fmt.Println("Error converting operand:", err)
+					return
+				}
 			}
 			if calculate2(testValue, operands) {
-				result += testValue
+				result = result.Add(testValue)
 			}
 		}
 	}
 	if err := scanner.Err(); err != nil {
 		fmt.Println("Error reading file: ", err)
 	}
-	// We should have the grid and the starting position now. Let's navigate the grid
+	if err := result.Err(); err != nil {
+		fmt.Println("Error computing result:", err)
+		return
+	}
 	fmt.Println("The final result is: ", result)
 }
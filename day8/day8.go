@@ -4,8 +4,9 @@ import (
 	"bufio"
 	"fmt"
 	"math"
-	"os"
 	"time"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
 func timer(name string) func() {
@@ -81,7 +82,7 @@ func calculateAntiNodeCoordinates(coord1 Coordinate, coord2 Coordinate, rows int
 func part1() {
 	// https://adventofcode.com/2024/day/7
 	// Walk an operations tree and determine a valid order of operations
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
@@ -158,7 +159,7 @@ func calculateAllAntiNodes(coord1 Coordinate, coord2 Coordinate, rows int, colum
 
 func part2() {
 	// https://adventofcode.com/2024/day/8#part2
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
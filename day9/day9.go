@@ -3,11 +3,14 @@ package main
 import (
 	"bufio"
 	"container/list"
+	"flag"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/harvardpan/advent-of-code-2024/internal/answer"
+	"github.com/harvardpan/advent-of-code-2024/internal/input"
 )
 
 func timer(name string) func() {
@@ -17,7 +20,12 @@ func timer(name string) func() {
 	}
 }
 
+// Set by --bigint to keep the checksum in an answer.Int backed by math/big.
+var useBigInt bool
+
 func main() {
+	flag.BoolVar(&useBigInt, "bigint", false, "use arbitrary-precision integers for the checksum")
+	flag.Parse()
 	part1()
 	part2()
 }
@@ -39,8 +47,8 @@ func findNextInsertIndex(startElement *list.Element, endElement *list.Element) *
 	return nil
 }
 
-func calculateResult(disk *list.List) int {
-	result := 0
+func calculateResult(disk *list.List) answer.Int {
+	result := answer.New(0, useBigInt)
 	index := 0
 	for e := disk.Front(); e != nil; e = e.Next() {
 		if e.Value.(Block).fileId == -1 {
@@ -48,7 +56,7 @@ func calculateResult(disk *list.List) int {
 			continue
 		}
 		for j := 0; j < e.Value.(Block).size; j++ {
-			result += e.Value.(Block).fileId * index
+			result = result.Add(answer.New(e.Value.(Block).fileId, useBigInt).Mul(answer.New(index, useBigInt)))
 			index++
 		}
 	}
@@ -70,7 +78,7 @@ func printDisk(disk *list.List) {
 func part1() {
 	// https://adventofcode.com/2024/day/9
 	// defragment the disk and fill in all the space
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
@@ -139,7 +147,12 @@ func part1() {
 		}
 	}
 	printDisk(disk)
-	fmt.Println("The final result is: ", calculateResult(disk))
+	result := calculateResult(disk)
+	if err := result.Err(); err != nil {
+		fmt.Println("Error computing result:", err)
+		return
+	}
+	fmt.Println("The final result is: ", result)
 }
 
 func findEmptyBlockWithEnoughSpace(startElement *list.Element, endElement *list.Element, size int) *list.Element {
@@ -160,7 +173,7 @@ func findEmptyBlockWithEnoughSpace(startElement *list.Element, endElement *list.
 func part2() {
 	// https://adventofcode.com/2024/day/9#part2
 	// Only defragment file when the entire block can fit
-	file, err := os.Open("input.txt")
+	file, err := input.Open("input.txt")
 	if err != nil {
 		fmt.Println("Error opening file:", err)
 		return
@@ -224,5 +237,10 @@ func part2() {
 		}
 	}
 	printDisk(disk)
-	fmt.Println("The final result is: ", calculateResult(disk))
+	result := calculateResult(disk)
+	if err := result.Err(); err != nil {
+		fmt.Println("Error computing result:", err)
+		return
+	}
+	fmt.Println("The final result is: ", result)
 }
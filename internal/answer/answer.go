@@ -0,0 +1,163 @@
+// Package answer provides an integer type for puzzle answers that can outgrow
+// int. An Int is either a machine int whose arithmetic is checked for
+// overflow, or a *big.Int when arbitrary precision was asked for.
+//
+// Overflow is sticky, like NaN: once an operation overflows, every Int derived
+// from it reports ErrOverflow from Err, so a solver only has to check its final
+// result.
+//
+// The days that use it take a --bigint flag. Without it answers stay in int
+// and a result too big for int is reported as an overflow error; with it they
+// are kept in math/big and always come out exact.
+package answer
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// ErrOverflow is reported by Err when a computation didn't fit in an int.
+var ErrOverflow = errors.New("integer overflow, rerun with --bigint")
+
+type Int struct {
+	small    int
+	big      *big.Int // non-nil when backed by math/big
+	overflow bool
+}
+
+// New returns v as an Int, backed by math/big when useBig is set.
+func New(v int, useBig bool) Int {
+	if useBig {
+		return Int{big: big.NewInt(int64(v))}
+	}
+	return Int{small: v}
+}
+
+// Parse reads a decimal number. Without useBig, a number that doesn't fit in
+// an int is reported as ErrOverflow rather than being truncated. An empty
+// string parses as zero.
+func Parse(s string, useBig bool) (Int, error) {
+	if s == "" {
+		return New(0, useBig), nil
+	}
+	if useBig {
+		v, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return Int{}, &strconv.NumError{Func: "Parse", Num: s, Err: strconv.ErrSyntax}
+		}
+		return Int{big: v}, nil
+	}
+	v, err := strconv.Atoi(s)
+	if errors.Is(err, strconv.ErrRange) {
+		return Int{overflow: true}, ErrOverflow
+	}
+	return Int{small: v}, err
+}
+
+func (x Int) IsBig() bool {
+	return x.big != nil
+}
+
+// Err returns ErrOverflow if x, or anything it was computed from, overflowed.
+func (x Int) Err() error {
+	if x.overflow {
+		return ErrOverflow
+	}
+	return nil
+}
+
+func (x Int) toBig() *big.Int {
+	if x.big != nil {
+		return x.big
+	}
+	return big.NewInt(int64(x.small))
+}
+
+// binary runs a two-operand operation: on big.Ints if either side is big,
+// otherwise with the checked int operation.
+func binary(x, y Int, bigOp func(z, a, b *big.Int) *big.Int, smallOp func(a, b int) (int, bool)) Int {
+	if x.overflow || y.overflow {
+		return Int{overflow: true}
+	}
+	if x.big != nil || y.big != nil {
+		return Int{big: bigOp(new(big.Int), x.toBig(), y.toBig())}
+	}
+	v, ok := smallOp(x.small, y.small)
+	if !ok {
+		return Int{overflow: true}
+	}
+	return Int{small: v}
+}
+
+func (x Int) Add(y Int) Int {
+	return binary(x, y, (*big.Int).Add, func(a, b int) (int, bool) {
+		c := a + b
+		return c, !((a > 0 && b > 0 && c < 0) || (a < 0 && b < 0 && c >= 0))
+	})
+}
+
+func (x Int) Sub(y Int) Int {
+	return binary(x, y, (*big.Int).Sub, func(a, b int) (int, bool) {
+		c := a - b
+		return c, !((a >= 0 && b < 0 && c < 0) || (a < 0 && b > 0 && c >= 0))
+	})
+}
+
+func (x Int) Mul(y Int) Int {
+	return binary(x, y, (*big.Int).Mul, func(a, b int) (int, bool) {
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		c := a * b
+		return c, c/b == a && !(a == -1 && b == math.MinInt) && !(b == -1 && a == math.MinInt)
+	})
+}
+
+// Quo divides x by y, truncating towards zero like Go's / operator. It panics
+// if y is zero.
+func (x Int) Quo(y Int) Int {
+	return binary(x, y, (*big.Int).Quo, func(a, b int) (int, bool) {
+		return a / b, !(a == math.MinInt && b == -1)
+	})
+}
+
+// Rem is the remainder of Quo, with the sign of x like Go's % operator.
+func (x Int) Rem(y Int) Int {
+	return binary(x, y, (*big.Int).Rem, func(a, b int) (int, bool) {
+		if b == -1 {
+			return 0, true
+		}
+		return a % b, true
+	})
+}
+
+// Cmp returns -1, 0 or +1 depending on whether x is less than, equal to or
+// greater than y. Overflowed values compare as zero.
+func (x Int) Cmp(y Int) int {
+	if x.big != nil || y.big != nil {
+		return x.toBig().Cmp(y.toBig())
+	}
+	switch {
+	case x.small < y.small:
+		return -1
+	case x.small > y.small:
+		return 1
+	}
+	return 0
+}
+
+func (x Int) Sign() int {
+	return x.Cmp(Int{})
+}
+
+func (x Int) String() string {
+	if x.overflow {
+		return "overflow"
+	}
+	if x.big != nil {
+		return x.big.String()
+	}
+	return strconv.Itoa(x.small)
+}
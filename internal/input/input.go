@@ -0,0 +1,158 @@
+// Package input loads puzzle inputs. Puzzle authors ask that inputs aren't
+// published, so every day's input.txt is committed encrypted as input.txt.enc
+// and the plain file is ignored by git. Open and ReadFile read the plain file
+// when it exists and otherwise decrypt the .enc file with the key from the
+// environment: $AOC_INPUT_KEY holds the 64 hex characters of the key, or
+// $AOC_INPUT_KEYFILE names a file holding them. "go run ./aoc inputs keygen"
+// makes a new key.
+//
+// Without a key, as in CI, only the sample inputs can be run; opening a real
+// input fails with ErrNoKey.
+package input
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// EncryptedSuffix is appended to an input's file name once it is encrypted.
+const EncryptedSuffix = ".enc"
+
+// The key is 32 bytes (AES-256), written as 64 hex characters, either directly
+// in $AOC_INPUT_KEY or in the file named by $AOC_INPUT_KEYFILE.
+const (
+	KeyEnv     = "AOC_INPUT_KEY"
+	KeyFileEnv = "AOC_INPUT_KEYFILE"
+)
+
+// Encrypted files start with this header, followed by the GCM nonce and the
+// sealed contents.
+var magic = []byte("AOCENC1\n")
+
+// ErrNoKey is returned when an input is only available encrypted and no key is
+// configured.
+var ErrNoKey = errors.New("no input key set, export " + KeyEnv + " or " + KeyFileEnv)
+
+// Key reads the input key from the environment.
+func Key() ([]byte, error) {
+	encoded := os.Getenv(KeyEnv)
+	if encoded == "" {
+		keyFile := os.Getenv(KeyFileEnv)
+		if keyFile == "" {
+			return nil, ErrNoKey
+		}
+		contents, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading key file: %w", err)
+		}
+		encoded = string(contents)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("input key is not hex: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("input key should be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// NewKey returns a random key, hex encoded.
+func NewKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt seals plaintext with AES-GCM under a fresh random nonce.
+func Encrypt(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := append([]byte{}, magic...)
+	sealed = append(sealed, nonce...)
+	return gcm.Seal(sealed, nonce, plaintext, magic), nil
+}
+
+// Decrypt reverses Encrypt. A wrong key or a modified file fails authentication.
+func Decrypt(key, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(sealed, magic) || len(sealed) < len(magic)+gcm.NonceSize() {
+		return nil, errors.New("not an encrypted input")
+	}
+	sealed = sealed[len(magic):]
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, magic)
+	if err != nil {
+		return nil, errors.New("decryption failed, is the input key right?")
+	}
+	return plaintext, nil
+}
+
+// ReadFile returns the contents of name, decrypting name.enc if only the
+// encrypted copy exists.
+func ReadFile(name string) ([]byte, error) {
+	contents, err := os.ReadFile(name)
+	if err == nil || !errors.Is(err, os.ErrNotExist) {
+		return contents, err
+	}
+	sealed, encErr := os.ReadFile(name + EncryptedSuffix)
+	if errors.Is(encErr, os.ErrNotExist) {
+		// neither exists, report the plain name
+		return nil, err
+	}
+	if encErr != nil {
+		return nil, encErr
+	}
+	key, err := Key()
+	if err != nil {
+		return nil, fmt.Errorf("%s is encrypted: %w", name, err)
+	}
+	plaintext, err := Decrypt(key, sealed)
+	if err != nil {
+		return nil, fmt.Errorf("%s%s: %w", name, EncryptedSuffix, err)
+	}
+	return plaintext, nil
+}
+
+// Open is the drop-in replacement for os.Open used by the days.
+func Open(name string) (io.ReadCloser, error) {
+	file, err := os.Open(name)
+	if err == nil {
+		return file, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	contents, err := ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(contents)), nil
+}